@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// An alert rule fires when an endpoint or pod whose name contains Match
+// transitions into one of the listed statuses. For "slo" rules the status is
+// the burn state of the endpoint's SLOs: SLOW_BURN or FAST_BURN.
+type alertRule struct {
+	Name   string   `json:"name"`
+	Source string   `json:"source"` // "endpoint", "pod" or "slo"
+	Match  string   `json:"match"`  // empty matches everything
+	Status []string `json:"status"`
+}
+
+func defaultAlertRules() []alertRule {
+	return []alertRule{
+		{Name: "Endpoint unhealthy", Source: "endpoint", Status: []string{"FAIL", "ERROR", "TIMEOUT"}},
+		{Name: "Error budget fast burn", Source: "slo", Status: []string{"FAST_BURN"}},
+		{Name: "Pod failing", Source: "pod", Status: []string{"Error", "Evicted", "CrashLoopBackOff", "ImagePullBackOff", "ErrImagePull"}},
+	}
+}
+
+func (r alertRule) matches(name, status string) bool {
+	if r.Match != "" && !strings.Contains(name, r.Match) {
+		return false
+	}
+	for _, s := range r.Status {
+		if strings.EqualFold(s, status) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateAlertRules returns a notification for every rule/object pair that
+// started matching since the previous evaluation of the same source. firing
+// is updated in place so a rule only fires again after it has cleared.
+func evaluateAlertRules(rules []alertRule, source string, statuses map[string]string, firing map[string]bool, kubeContext string) []notification {
+	var fired []notification
+	active := map[string]bool{}
+	for _, rule := range rules {
+		if rule.Source != source {
+			continue
+		}
+		for name, status := range statuses {
+			if !rule.matches(name, status) {
+				continue
+			}
+			key := source + "|" + rule.Name + "|" + name
+			active[key] = true
+			if firing[key] {
+				continue
+			}
+			firing[key] = true
+			n := notification{
+				Event:       "alert",
+				Title:       rule.Name,
+				Status:      status,
+				KubeContext: kubeContext,
+				Time:        time.Now(),
+			}
+			if source == "pod" {
+				n.Pod = name
+			} else {
+				n.Endpoint = name
+			}
+			fired = append(fired, n)
+		}
+	}
+	for key := range firing {
+		if strings.HasPrefix(key, source+"|") && !active[key] {
+			delete(firing, key)
+		}
+	}
+	return fired
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// config is loaded from $ONCALL_CONFIG or <user config dir>/oncall/config.json.
+// A missing file is not an error; the defaults mirror the previously hard-coded setup.
+type config struct {
+	Sentry       sentryConfig        `json:"sentry"`
+	HealthChecks []healthCheckConfig `json:"healthChecks"`
+	Webhooks     []webhookConfig     `json:"webhooks"`
+	DryRun       bool                `json:"dryRun"`
+	AlertRules   []alertRule         `json:"alertRules"`
+	// Keyed by kube context name; "default" applies to all other contexts
+	Environments map[string]environmentConfig `json:"environments"`
+	// Upper bound for a whole probe round across all checks
+	HealthTimeoutMS int `json:"healthTimeoutMs"`
+	// Days of probe, Sentry count and pod history kept on disk; defaults to 14
+	HistoryRetentionDays int `json:"historyRetentionDays"`
+	// Incident report output directory (default <state dir>/reports) and a
+	// text/template file replacing the built-in Markdown layout
+	ReportDir      string `json:"reportDir"`
+	ReportTemplate string `json:"reportTemplate"`
+	// Shift length covered by the handoff summary; defaults to 12
+	HandoffHours int `json:"handoffHours"`
+	// Namespaces whose Warning events are listed in the events pane; the
+	// current namespace when empty, "*" for all namespaces
+	WatchedNamespaces []string `json:"watchedNamespaces"`
+	// Disables the pod actions (delete, restart, scale, cordon) everywhere;
+	// environments can also be made read-only one by one
+	ReadOnly bool `json:"readOnly"`
+	// Sentry issues first seen within this many minutes after a deploy of
+	// their service are marked in the Sentry pane
+	DeployWindowMinutes int `json:"deployWindowMinutes"`
+	// Pods using at least this percentage of their CPU or memory limit are
+	// highlighted in the pods pane
+	HighCPUPercent    int `json:"highCpuPercent"`
+	HighMemoryPercent int `json:"highMemoryPercent"`
+}
+
+type sentryConfig struct {
+	Org      string                `json:"org"`
+	Projects []sentryProjectConfig `json:"projects"`
+	// Sentry search syntax, e.g. "is:unresolved level:error release:1.2.3"
+	DefaultQuery string `json:"defaultQuery"`
+	// Selectable with the number keys 1-9 in the Sentry pane
+	SavedQueries []savedQuery `json:"savedQueries"`
+}
+
+type savedQuery struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+type sentryProjectConfig struct {
+	Slug  string `json:"slug"`
+	Label string `json:"label"` // short name used in the Analytics pane
+	// Deployments of the service, for marking issues that follow a deploy;
+	// defaults to Deployments named like the slug or the lowercased label
+	Deployments []string `json:"deployments"`
+}
+
+type healthCheckConfig struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// "http" (default), "tcp", "dns", "grpc", "postgres" or "redis"
+	Type string `json:"type"`
+	// host:port for tcp, grpc, postgres and redis; the name to resolve for dns
+	Address string `json:"address"`
+	// DNS record type (A, AAAA, CNAME, TXT or MX; default A) and values that
+	// must be among the answers
+	RecordType string   `json:"recordType"`
+	Expect     []string `json:"expect"`
+	// gRPC health service name (empty checks the whole server) and whether to
+	// connect with TLS instead of plaintext HTTP/2
+	Service string `json:"service"`
+	TLS     bool   `json:"tls"`
+	// Accepted HTTP status codes; any 2xx when empty
+	ExpectStatus []int `json:"expectStatus"`
+	// Fail when the response takes longer; unlimited when 0
+	MaxLatencyMS int `json:"maxLatencyMs"`
+	// Give up on the request after this long; defaults to 5000
+	TimeoutMS int `json:"timeoutMs"`
+	// Certificate expiry thresholds in days for HTTPS checks (defaults 21 and 7)
+	TLSWarnDays     int `json:"tlsWarnDays"`
+	TLSCriticalDays int `json:"tlsCriticalDays"`
+	// Regular expression the body must match
+	BodyRegex string `json:"bodyRegex"`
+	// Defaults to an optional "$.status" that must be "ok" or "up"
+	JSONPath []jsonPathAssertion `json:"jsonPath"`
+	// Extra request headers; values may be "env:NAME" or "file:/path" secret
+	// references and must be for credential headers such as Authorization
+	Headers map[string]string `json:"headers"`
+	// Credentials, always given as secret references; basicAuth is also the
+	// Redis AUTH password and optional ACL user
+	BearerToken string           `json:"bearerToken"`
+	BasicAuth   *basicAuthConfig `json:"basicAuth"`
+	// PEM client certificate and key for mTLS, and extra CA certificates to
+	// trust, as secret references
+	ClientCert string `json:"clientCert"`
+	ClientKey  string `json:"clientKey"`
+	CACert     string `json:"caCert"`
+	// Availability/latency objectives computed from the probe history
+	SLO *sloConfig `json:"slo"`
+}
+
+type sloConfig struct {
+	// Percent of probes that must succeed (OK or WARN), e.g. 99.9
+	Availability float64 `json:"availability"`
+	// LatencyTarget percent of answered probes must be faster than LatencyMS
+	LatencyMS     int     `json:"latencyMs"`
+	LatencyTarget float64 `json:"latencyTarget"`
+	// Rolling error budget window; defaults to 7 days
+	WindowHours int `json:"windowHours"`
+}
+
+type basicAuthConfig struct {
+	Username string `json:"username"` // literal or secret reference
+	Password string `json:"password"` // secret reference
+}
+
+// Asserts the value at Path equals Equals or one of OneOf. Without either,
+// the path only has to exist. Optional assertions are skipped when the path
+// is missing.
+type jsonPathAssertion struct {
+	Path     string `json:"path"`
+	Equals   any    `json:"equals"`
+	OneOf    []any  `json:"oneOf"`
+	Optional bool   `json:"optional"`
+}
+
+func defaultJSONPathAssertions() []jsonPathAssertion {
+	return []jsonPathAssertion{{Path: "$.status", OneOf: []any{"ok", "up"}, Optional: true}}
+}
+
+// Per-context settings: URL templates (text/template) used by the open/copy
+// link actions and the read-only switch
+type environmentConfig struct {
+	// Data: .ID .ShortID .Project .Org .Permalink; defaults to the Sentry permalink
+	IssueURL string `json:"issueUrl"`
+	// Data: .Pod .Namespace .Context; no default
+	PodURL string `json:"podUrl"`
+	// Data: .Name .URL; defaults to the health URL
+	EndpointURL string `json:"endpointUrl"`
+	// Disables the pod actions for this context
+	ReadOnly bool `json:"readOnly"`
+}
+
+type webhookConfig struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Format is "slack" (Slack/Teams incoming webhook) or "json" (generic payload)
+	Format     string `json:"format"`
+	MaxRetries int    `json:"maxRetries"`
+}
+
+func defaultConfig() config {
+	return config{
+		Sentry: sentryConfig{
+			Org: "siip",
+			Projects: []sentryProjectConfig{
+				{Slug: "siip-ticketing", Label: "Ticketing"},
+				{Slug: "siip-iam-service", Label: "IAM"},
+			},
+			DefaultQuery: "age:-24h is:unresolved",
+		},
+		HealthTimeoutMS:      10000,
+		HistoryRetentionDays: 14,
+		HandoffHours:         12,
+		DeployWindowMinutes:  30,
+		HighCPUPercent:       80,
+		HighMemoryPercent:    80,
+		HealthChecks:         defaultHealthChecks(),
+		AlertRules:           defaultAlertRules(),
+	}
+}
+
+// defaultHealthChecks are used when the config has no healthChecks; a
+// configured list replaces them entirely
+func defaultHealthChecks() []healthCheckConfig {
+	return []healthCheckConfig{
+		{Name: "Ticketing API", URL: "https://ticketing.siip.io/health", TimeoutMS: 5000, TLSWarnDays: 21, TLSCriticalDays: 7, JSONPath: defaultJSONPathAssertions()},
+		{Name: "IAM API", URL: "https://iam.siip.io/health", TimeoutMS: 5000, TLSWarnDays: 21, TLSCriticalDays: 7, JSONPath: defaultJSONPathAssertions()},
+	}
+}
+
+func configPath() string {
+	if p := os.Getenv("ONCALL_CONFIG"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "oncall.json"
+	}
+	return filepath.Join(dir, "oncall", "config.json")
+}
+
+func loadConfig(path string) (config, error) {
+	defaults := defaultConfig()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return defaults, nil
+	}
+	if err != nil {
+		return defaults, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	// Decoding on top of the defaults would reuse the elements of the default
+	// lists and leak their fields into the configured entries
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return defaults, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if cfg.Sentry.Org == "" {
+		cfg.Sentry.Org = defaults.Sentry.Org
+	}
+	if cfg.Sentry.Projects == nil {
+		cfg.Sentry.Projects = defaults.Sentry.Projects
+	}
+	if cfg.Sentry.DefaultQuery == "" {
+		cfg.Sentry.DefaultQuery = defaults.Sentry.DefaultQuery
+	}
+	if cfg.HealthChecks == nil {
+		cfg.HealthChecks = defaultHealthChecks()
+	}
+	for i := range cfg.Sentry.Projects {
+		if cfg.Sentry.Projects[i].Label == "" {
+			cfg.Sentry.Projects[i].Label = cfg.Sentry.Projects[i].Slug
+		}
+	}
+	for i := range cfg.HealthChecks {
+		if cfg.HealthChecks[i].Type == "" {
+			cfg.HealthChecks[i].Type = "http"
+		}
+		if cfg.HealthChecks[i].Type == "http" && len(cfg.HealthChecks[i].JSONPath) == 0 {
+			cfg.HealthChecks[i].JSONPath = defaultJSONPathAssertions()
+		}
+		if cfg.HealthChecks[i].TimeoutMS <= 0 {
+			cfg.HealthChecks[i].TimeoutMS = 5000
+		}
+		if cfg.HealthChecks[i].TLSWarnDays <= 0 {
+			cfg.HealthChecks[i].TLSWarnDays = 21
+		}
+		if cfg.HealthChecks[i].TLSCriticalDays <= 0 {
+			cfg.HealthChecks[i].TLSCriticalDays = 7
+		}
+		if slo := cfg.HealthChecks[i].SLO; slo != nil && slo.WindowHours <= 0 {
+			slo.WindowHours = 7 * 24
+		}
+	}
+	if cfg.HealthTimeoutMS <= 0 {
+		cfg.HealthTimeoutMS = 10000
+	}
+	if cfg.HistoryRetentionDays <= 0 {
+		cfg.HistoryRetentionDays = 14
+	}
+	if cfg.HandoffHours <= 0 {
+		cfg.HandoffHours = 12
+	}
+	if cfg.DeployWindowMinutes <= 0 {
+		cfg.DeployWindowMinutes = 30
+	}
+	if cfg.HighCPUPercent <= 0 {
+		cfg.HighCPUPercent = 80
+	}
+	if cfg.HighMemoryPercent <= 0 {
+		cfg.HighMemoryPercent = 80
+	}
+	if len(cfg.AlertRules) == 0 {
+		cfg.AlertRules = defaultAlertRules()
+	}
+	for i := range cfg.Webhooks {
+		if cfg.Webhooks[i].Format == "" {
+			cfg.Webhooks[i].Format = "slack"
+		}
+		if cfg.Webhooks[i].MaxRetries <= 0 {
+			cfg.Webhooks[i].MaxRetries = 3
+		}
+	}
+	return cfg, nil
+}
+
+// Environment settings for a kube context, falling back to "default"
+func (c config) environment(kubeContext string) environmentConfig {
+	if env, ok := c.Environments[kubeContext]; ok {
+		return env
+	}
+	return c.Environments["default"]
+}
+
+// Pod actions are refused in read-only mode
+func (c config) readOnly(kubeContext string) bool {
+	return c.ReadOnly || c.environment(kubeContext).ReadOnly
+}
+
+// Directory for files that must survive restarts (seen issues, history, notes, ...)
+func stateDir() string {
+	if d := os.Getenv("ONCALL_STATE_DIR"); d != "" {
+		return d
+	}
+	if d, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(d, "oncall")
+	}
+	return ".oncall"
+}
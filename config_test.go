@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	cfg, err := loadConfig(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, defaultConfig()) {
+		t.Errorf("got %+v, want the defaults", cfg)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	if _, err := loadConfig(writeConfig(t, `{"healthChecks": [`)); err == nil {
+		t.Error("want a parse error")
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	cfg, err := loadConfig(writeConfig(t, `{
+		"sentry": {"projects": [{"slug": "billing"}]},
+		"healthChecks": [
+			{"name": "api", "url": "https://api.example.test/health", "slo": {"availability": 99.9}},
+			{"name": "tuned", "url": "https://tuned.example.test/health", "timeoutMs": 800, "tlsWarnDays": 30, "tlsCriticalDays": 3,
+			 "jsonPath": [{"path": "$.ok", "equals": true}], "slo": {"availability": 99, "windowHours": 24}},
+			{"name": "redis", "type": "redis", "address": "redis:6379"}
+		],
+		"webhooks": [{"name": "ops", "url": "https://hooks.example.test/ops"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults := defaultConfig()
+	if cfg.Sentry.Org != defaults.Sentry.Org || cfg.Sentry.DefaultQuery != defaults.Sentry.DefaultQuery {
+		t.Errorf("sentry org %q and query %q not defaulted", cfg.Sentry.Org, cfg.Sentry.DefaultQuery)
+	}
+	if len(cfg.Sentry.Projects) != 1 || cfg.Sentry.Projects[0].Label != "billing" {
+		t.Errorf("projects %+v, want billing labelled by its slug", cfg.Sentry.Projects)
+	}
+
+	api, tuned, redis := cfg.HealthChecks[0], cfg.HealthChecks[1], cfg.HealthChecks[2]
+	if api.Type != "http" || api.TimeoutMS != 5000 || api.TLSWarnDays != 21 || api.TLSCriticalDays != 7 || api.SLO.WindowHours != 7*24 {
+		t.Errorf("api check not defaulted: %+v %+v", api, api.SLO)
+	}
+	if !reflect.DeepEqual(api.JSONPath, defaultJSONPathAssertions()) {
+		t.Errorf("api jsonPath %+v, want the default status assertion", api.JSONPath)
+	}
+	if tuned.TimeoutMS != 800 || tuned.TLSWarnDays != 30 || tuned.TLSCriticalDays != 3 || tuned.SLO.WindowHours != 24 {
+		t.Errorf("tuned check overridden: %+v %+v", tuned, tuned.SLO)
+	}
+	if len(tuned.JSONPath) != 1 || tuned.JSONPath[0].Path != "$.ok" {
+		t.Errorf("tuned jsonPath %+v, want only the configured assertion", tuned.JSONPath)
+	}
+	if redis.JSONPath != nil || redis.TimeoutMS != 5000 {
+		t.Errorf("redis check %+v, want a timeout and no jsonPath", redis)
+	}
+
+	if cfg.HealthTimeoutMS != 10000 || cfg.HistoryRetentionDays != 14 || cfg.HandoffHours != 12 || cfg.DeployWindowMinutes != 30 || cfg.HighCPUPercent != 80 || cfg.HighMemoryPercent != 80 {
+		t.Errorf("global defaults not applied: %+v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.AlertRules, defaultAlertRules()) {
+		t.Errorf("alert rules %+v, want the defaults", cfg.AlertRules)
+	}
+	if w := cfg.Webhooks[0]; w.Format != "slack" || w.MaxRetries != 3 {
+		t.Errorf("webhook %+v, want slack format and 3 retries", w)
+	}
+}
+
+func TestLoadConfigDefaultListsAreNotShared(t *testing.T) {
+	cfg, err := loadConfig(writeConfig(t, `{"healthChecks": [{"name": "only", "url": "https://only.example.test"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.HealthChecks) != 1 || cfg.HealthChecks[0].Name != "only" {
+		t.Errorf("got %+v, want the configured check to replace the defaults", cfg.HealthChecks)
+	}
+}
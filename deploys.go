@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// deploy is a Deployment moving to a new revision, i.e. a new ReplicaSet
+type deploy struct {
+	Time       time.Time
+	Scope      string // kube context/namespace
+	Deployment string
+	Revision   string
+}
+
+// Revisions are keyed by scope and name, as Deployments of the same name in
+// other contexts or namespaces are unrelated
+func revisionKey(scope, deployment string) string {
+	return scope + "/" + deployment
+}
+
+func deployOf(r historyRecord) deploy {
+	return deploy{Time: r.Time, Scope: r.Scope, Deployment: r.Name, Revision: r.Status}
+}
+
+// restoreDeploys returns the last known revision of every Deployment and the
+// deploys in the stored history
+func restoreDeploys(records []historyRecord) (map[string]string, []deploy) {
+	revisions := map[string]string{}
+	var deploys []deploy
+	for _, r := range records {
+		if r.Kind == "deploy" && r.Scope != "" {
+			revisions[revisionKey(r.Scope, r.Name)] = r.Status
+			deploys = append(deploys, deployOf(r))
+		}
+	}
+	return revisions, deploys
+}
+
+// detectDeploys compares the Deployment revisions with those seen before and
+// updates them; scope is the kube context/namespace the workloads were
+// listed in. Deployments seen for the first time are only remembered. A
+// deploy is dated by the creation of its ReplicaSet, unless that is older
+// than the previous deploy, as when rolling back to an earlier ReplicaSet.
+func detectDeploys(revisions map[string]string, deploys []deploy, scope string, workloads []workload, now time.Time) []historyRecord {
+	var records []historyRecord
+	for _, w := range workloads {
+		if w.Kind != "Deployment" || w.Revision == "" {
+			continue
+		}
+		key := revisionKey(scope, w.Name)
+		previous, known := revisions[key]
+		revisions[key] = w.Revision
+		if !known || previous == w.Revision {
+			continue
+		}
+		at := now
+		if created := w.RevisionCreated; !created.IsZero() && created.Before(now) {
+			if last, ok := lastDeploy(deploys, scope, w.Name); !ok || created.After(last.Time) {
+				at = created
+			}
+		}
+		records = append(records, historyRecord{
+			Time:   at,
+			Kind:   "deploy",
+			Name:   w.Name,
+			Scope:  scope,
+			Status: w.Revision,
+			From:   previous,
+			Title:  fmt.Sprintf("Deploy of %s: revision %s → %s (%s)", w.Name, previous, w.Revision, strings.Join(w.Images, ", ")),
+		})
+	}
+	return records
+}
+
+func lastDeploy(deploys []deploy, scope, deployment string) (deploy, bool) {
+	for i := len(deploys) - 1; i >= 0; i-- {
+		if deploys[i].Scope == scope && deploys[i].Deployment == deployment {
+			return deploys[i], true
+		}
+	}
+	return deploy{}, false
+}
+
+// deployProgress words the rollout state of a Deployment with its revision,
+// e.g. "deploying rev 5: 2 of 3 updated"
+func deployProgress(w workload) string {
+	state, detail, _ := strings.Cut(w.Progress, ": ")
+	switch state {
+	case "rolling out":
+		return fmt.Sprintf("deploying rev %s: %s", w.Revision, detail)
+	case "stalled":
+		return fmt.Sprintf("deploy of rev %s stalled: %s", w.Revision, detail)
+	}
+	return fmt.Sprintf("rev %s %s", w.Revision, w.Progress)
+}
+
+// renderDeploying is a line per Deployment still rolling out, shown above the
+// pods
+func renderDeploying(workloads []workload) string {
+	var lines []string
+	for _, w := range workloads {
+		if w.Kind != "Deployment" || w.Progress == "complete" {
+			continue
+		}
+		text := w.Name + " " + w.Progress
+		if w.Revision != "" {
+			text = w.Name + " " + deployProgress(w)
+		}
+		if strings.HasPrefix(w.Progress, "stalled") {
+			lines = append(lines, statusUnresolvedStyle.Render("✗ "+text))
+		} else {
+			lines = append(lines, pendingStyle.Render("⟳ "+text))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// projectDeployments are the Deployments of a Sentry project's service: those
+// configured, or else the ones named like the project slug or label
+func projectDeployments(projects []sentryProjectConfig, slug string) []string {
+	for _, p := range projects {
+		if p.Slug != slug {
+			continue
+		}
+		if len(p.Deployments) > 0 {
+			return p.Deployments
+		}
+		return []string{p.Slug, strings.ToLower(p.Label)}
+	}
+	return []string{slug}
+}
+
+// markDeployIssues sets AfterDeploy on issues first seen within window after a
+// deploy of their service, naming the latest such deploy
+func markDeployIssues(issues []sentryIssue, deploys []deploy, projects []sentryProjectConfig, window time.Duration) {
+	for i := range issues {
+		issues[i].AfterDeploy = ""
+		deployments := projectDeployments(projects, issues[i].Project)
+		for j := len(deploys) - 1; j >= 0; j-- {
+			d := deploys[j]
+			first := issues[i].FirstSeen
+			if first.Before(d.Time) || first.Sub(d.Time) > window || !slices.Contains(deployments, d.Deployment) {
+				continue
+			}
+			issues[i].AfterDeploy = fmt.Sprintf("%s rev %s +%s", d.Deployment, d.Revision, formatDuration(first.Sub(d.Time)))
+			break
+		}
+	}
+}
+
+func afterDeployNote(issue sentryIssue) string {
+	if issue.AfterDeploy == "" {
+		return ""
+	}
+	return ", first seen after " + issue.AfterDeploy
+}
+
+func (m model) deployWindow() time.Duration {
+	return time.Duration(m.cfg.DeployWindowMinutes) * time.Minute
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectDeploys(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	api := func(revision string, created time.Time) []workload {
+		return []workload{
+			{Kind: "Deployment", Name: "api", Revision: revision, RevisionCreated: created, Images: []string{"api:" + revision}},
+			{Kind: "StatefulSet", Name: "db", Revision: "9"},
+			{Kind: "Deployment", Name: "no-revision"},
+		}
+	}
+	type refresh struct {
+		scope     string
+		workloads []workload
+		want      []string // "scope revision from→to at"
+	}
+	tests := []struct {
+		name      string
+		refreshes []refresh
+	}{
+		{"first refresh is a baseline", []refresh{
+			{"prod/default", api("4", now.Add(-time.Hour)), nil},
+		}},
+		{"unchanged revision", []refresh{
+			{"prod/default", api("4", now.Add(-time.Hour)), nil},
+			{"prod/default", api("4", now.Add(-time.Hour)), nil},
+		}},
+		{"new revision dated by its ReplicaSet", []refresh{
+			{"prod/default", api("4", now.Add(-time.Hour)), nil},
+			{"prod/default", api("5", now.Add(-time.Minute)), []string{"prod/default 4→5 11:59"}},
+		}},
+		{"ReplicaSet created in the future", []refresh{
+			{"prod/default", api("4", now.Add(-time.Hour)), nil},
+			{"prod/default", api("5", now.Add(time.Minute)), []string{"prod/default 4→5 12:00"}},
+		}},
+		{"same name in another scope is a separate baseline", []refresh{
+			{"prod/default", api("4", now.Add(-time.Hour)), nil},
+			{"staging/default", api("7", now.Add(-time.Minute)), nil},
+			{"prod/default", api("4", now.Add(-time.Hour)), nil},
+		}},
+		{"deploy while another scope was selected", []refresh{
+			{"prod/default", api("4", now.Add(-time.Hour)), nil},
+			{"staging/default", api("7", now.Add(-time.Hour)), nil},
+			{"prod/default", api("5", now.Add(-30*time.Minute)), []string{"prod/default 4→5 11:30"}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revisions := map[string]string{}
+			var deploys []deploy
+			for i, r := range tt.refreshes {
+				records := detectDeploys(revisions, deploys, r.scope, r.workloads, now)
+				var got []string
+				for _, rec := range records {
+					got = append(got, rec.Scope+" "+rec.From+"→"+rec.Status+" "+rec.Time.Format("15:04"))
+					deploys = append(deploys, deployOf(rec))
+				}
+				if len(got) != len(r.want) {
+					t.Fatalf("refresh %d: got %v, want %v", i, got, r.want)
+				}
+				for j := range got {
+					if got[j] != r.want[j] {
+						t.Errorf("refresh %d: got %v, want %v", i, got, r.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDetectDeploysRollback(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	revisions := map[string]string{revisionKey("prod/default", "api"): "5"}
+	deploys := []deploy{{Time: now.Add(-10 * time.Minute), Scope: "prod/default", Deployment: "api", Revision: "5"}}
+	// rolling back reuses the older ReplicaSet of revision 4
+	workloads := []workload{{Kind: "Deployment", Name: "api", Revision: "6", RevisionCreated: now.Add(-24 * time.Hour)}}
+	records := detectDeploys(revisions, deploys, "prod/default", workloads, now)
+	if len(records) != 1 || !records[0].Time.Equal(now) {
+		t.Fatalf("got %+v, want one deploy dated now", records)
+	}
+}
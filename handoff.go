@@ -0,0 +1,411 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// A gap between probes longer than this ends an outage at the last failed
+// probe, so time the TUI was not running is not counted as downtime
+const maxProbeGap = 5 * time.Minute
+
+// handoffSummary is what happened during a shift, built from the history,
+// the notes and the currently open Sentry issues
+type handoffSummary struct {
+	Since, Until time.Time
+	Opened       []historyRecord // NEW/REGRESSED issue records
+	Resolved     []historyRecord // resolve actions
+	StillOpen    []sentryIssue
+	OpenErr      string // why StillOpen is unavailable
+	Endpoints    []endpointOutages
+	Pods         []podTrouble
+	Notes        []incidentNote
+	Actions      []auditEntry // pod actions from the audit log
+	ActionsErr   string       // why Actions is unavailable
+}
+
+type endpointOutages struct {
+	Name      string
+	Outages   int
+	Downtime  time.Duration
+	Longest   time.Duration
+	StillDown bool
+}
+
+type podTrouble struct {
+	Name     string
+	Restarts int      // during the shift
+	Statuses []string // failure states seen, in order
+	Deleted  bool
+}
+
+// buildHandoff summarises records newer than since. Older records are only
+// used to know the state at the start of the shift.
+func buildHandoff(records []historyRecord, actions []auditEntry, notes []incidentNote, open []sentryIssue, since, until time.Time) handoffSummary {
+	h := handoffSummary{Since: since, Until: until, StillOpen: open}
+
+	type outage struct {
+		start, lastBad, lastSeen time.Time
+		down                     bool
+	}
+	outages := map[string]*outage{}
+	endpoints := map[string]*endpointOutages{}
+	var endpointOrder []string
+	restarts := map[string]int{}
+	pods := map[string]*podTrouble{}
+	var podOrder []string
+
+	endOutage := func(name string, o *outage, end time.Time) {
+		e := endpoints[name]
+		d := end.Sub(o.start)
+		e.Downtime += d
+		if d > e.Longest {
+			e.Longest = d
+		}
+		o.down = false
+	}
+
+	for _, r := range records {
+		inShift := !r.Time.Before(since)
+		switch r.Kind {
+		case "probe":
+			o := outages[r.Name]
+			if o == nil {
+				o = &outage{}
+				outages[r.Name] = o
+			}
+			bad := r.Status != "OK" && r.Status != "WARN"
+			if o.down && r.Time.Sub(o.lastSeen) > maxProbeGap {
+				if endpoints[r.Name] != nil {
+					endOutage(r.Name, o, o.lastBad)
+				}
+				o.down = false
+			}
+			o.lastSeen = r.Time
+			if !inShift {
+				// remember an outage in progress at the start of the shift
+				o.down = bad
+				o.start, o.lastBad = since, since
+				continue
+			}
+			if endpoints[r.Name] == nil && (bad || o.down) {
+				endpoints[r.Name] = &endpointOutages{Name: r.Name}
+				endpointOrder = append(endpointOrder, r.Name)
+				if o.down {
+					endpoints[r.Name].Outages++
+				}
+			}
+			switch {
+			case bad && !o.down:
+				o.down, o.start = true, r.Time
+				endpoints[r.Name].Outages++
+			case !bad && o.down:
+				endOutage(r.Name, o, r.Time)
+			}
+			if bad {
+				o.lastBad = r.Time
+			}
+		case "pod":
+			key := r.Scope + "/" + r.Name // the same pod name may exist in several contexts
+			prev, known := restarts[key]
+			restarts[key] = r.Restarts
+			if !inShift || r.Baseline {
+				continue
+			}
+			p := pods[key]
+			if p == nil {
+				p = &podTrouble{Name: r.Name}
+			}
+			if known && r.Restarts > prev {
+				p.Restarts += r.Restarts - prev
+			}
+			if r.Status == "Deleted" {
+				p.Deleted = true
+			} else if podLevel(r.Status) == "error" {
+				p.Statuses = append(p.Statuses, r.Status)
+			}
+			if pods[key] == nil && (p.Restarts > 0 || len(p.Statuses) > 0) {
+				pods[key] = p
+				podOrder = append(podOrder, key)
+			}
+		case "issue":
+			if inShift {
+				h.Opened = append(h.Opened, r)
+			}
+		case "action":
+			if inShift && r.Status == "resolve" {
+				h.Resolved = append(h.Resolved, r)
+			}
+		}
+	}
+
+	for _, name := range endpointOrder {
+		if o := outages[name]; o.down {
+			end := until
+			if until.Sub(o.lastSeen) > maxProbeGap {
+				end = o.lastBad
+			} else {
+				endpoints[name].StillDown = true
+			}
+			endOutage(name, o, end)
+		}
+		h.Endpoints = append(h.Endpoints, *endpoints[name])
+	}
+	for _, name := range podOrder {
+		h.Pods = append(h.Pods, *pods[name])
+	}
+	sort.SliceStable(h.Pods, func(i, j int) bool { return h.Pods[i].Restarts > h.Pods[j].Restarts })
+	for _, n := range notes {
+		if !n.Time.Before(since) && !n.Time.After(until) {
+			h.Notes = append(h.Notes, n)
+		}
+	}
+	for _, a := range actions {
+		if !a.Time.Before(since) && !a.Time.After(until) {
+			h.Actions = append(h.Actions, a)
+		}
+	}
+	return h
+}
+
+func (e endpointOutages) describe() string {
+	s := fmt.Sprintf("%d outage(s), %s down in total, longest %s", e.Outages, formatDuration(e.Downtime), formatDuration(e.Longest))
+	if e.StillDown {
+		s += ", still down"
+	}
+	return s
+}
+
+func (p podTrouble) describe() string {
+	var parts []string
+	if p.Restarts > 0 {
+		parts = append(parts, fmt.Sprintf("%d restart(s)", p.Restarts))
+	}
+	if len(p.Statuses) > 0 {
+		parts = append(parts, strings.Join(p.Statuses, " → "))
+	}
+	if p.Deleted {
+		parts = append(parts, "deleted")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%ds", int(d.Seconds()))
+}
+
+// render writes the summary as Markdown or, for chat, as plain text with
+// bullet characters instead of Markdown syntax
+func (h handoffSummary) render(markdown bool) string {
+	var b strings.Builder
+	heading := func(title string) {
+		if markdown {
+			fmt.Fprintf(&b, "\n## %s\n\n", title)
+		} else {
+			fmt.Fprintf(&b, "\n%s\n", strings.ToUpper(title))
+		}
+	}
+	item := func(format string, args ...any) {
+		bullet := "•"
+		if markdown {
+			bullet = "-"
+		}
+		fmt.Fprintf(&b, "%s %s\n", bullet, fmt.Sprintf(format, args...))
+	}
+	none := func(n int) {
+		if n == 0 {
+			item("none")
+		}
+	}
+	when := func(t time.Time) string { return t.Local().Format("01-02 15:04") }
+
+	title := fmt.Sprintf("Shift handoff %s – %s", when(h.Since), when(h.Until))
+	if markdown {
+		fmt.Fprintf(&b, "# %s\n", title)
+	} else {
+		fmt.Fprintf(&b, "%s\n", title)
+	}
+
+	heading("Sentry issues opened")
+	for _, r := range h.Opened {
+		item("%s %s %s: %s", when(r.Time), r.Status, r.Name, r.Title)
+	}
+	none(len(h.Opened))
+	heading("Sentry issues resolved")
+	for _, r := range h.Resolved {
+		item("%s %s", when(r.Time), r.Title)
+	}
+	none(len(h.Resolved))
+	heading("Sentry issues still open")
+	if h.OpenErr != "" {
+		item("unavailable: %s", h.OpenErr)
+	}
+	for _, issue := range h.StillOpen {
+		if markdown && issue.Permalink != "" {
+			item("[%s](%s) %s (%d events)", issue.ShortID, issue.Permalink, issue.Title, issue.Count)
+		} else {
+			item("%s %s (%d events)", issue.ShortID, issue.Title, issue.Count)
+		}
+	}
+	if h.OpenErr == "" {
+		none(len(h.StillOpen))
+	}
+	heading("Endpoints that went down")
+	for _, e := range h.Endpoints {
+		item("%s: %s", e.Name, e.describe())
+	}
+	none(len(h.Endpoints))
+	heading("Pods with restarts or failures")
+	for _, p := range h.Pods {
+		item("%s: %s", p.Name, p.describe())
+	}
+	none(len(h.Pods))
+	heading("Notes")
+	for _, n := range h.Notes {
+		item("%s %s", when(n.Time), n.summary())
+	}
+	none(len(h.Notes))
+	heading("Actions")
+	if h.ActionsErr != "" {
+		item("unavailable: %s", h.ActionsErr)
+	}
+	for _, a := range h.Actions {
+		result := ""
+		if a.Result == "error" {
+			result = " (failed)"
+		}
+		item("%s %s by %s on %s/%s%s", when(a.Time), a.Target, a.User, a.Context, a.Namespace, result)
+	}
+	if h.ActionsErr == "" {
+		none(len(h.Actions))
+	}
+	return b.String()
+}
+
+// runHandoff implements `oncall handoff [--since 12h] [--format markdown|text]`
+func runHandoff(cfg config, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("handoff", flag.ContinueOnError)
+	since := flags.Duration("since", time.Duration(cfg.HandoffHours)*time.Hour, "length of the shift to summarise")
+	format := flags.String("format", "markdown", "output format: markdown or text")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *format != "markdown" && *format != "text" {
+		return fmt.Errorf("unknown format %q, expected markdown or text", *format)
+	}
+
+	now := time.Now()
+	history := openHistoryStore(historyDir(), cfg.HistoryRetentionDays)
+	records := history.load(now.Add(-history.retention))
+	var open []sentryIssue
+	openErr := ""
+	if api, err := newSentryAPI(); err != nil {
+		openErr = err.Error()
+	} else {
+		for _, project := range cfg.Sentry.Projects {
+			issues, err := api.listIssues(cfg.Sentry.Org, project.Slug, cfg.Sentry.DefaultQuery, "lastSeen")
+			if err != nil {
+				openErr = err.Error()
+				break
+			}
+			open = append(open, withProject(issues, project.Slug)...)
+		}
+	}
+	actions, err := loadAuditLog(now.Add(-*since))
+	h := buildHandoff(records, actions, loadNotes(notesDir()), open, now.Add(-*since), now)
+	h.OpenErr = openErr
+	if err != nil {
+		h.ActionsErr = err.Error()
+	}
+	_, err = io.WriteString(out, h.render(*format == "markdown"))
+	return err
+}
+
+// handoffData is what the in-TUI handoff is built from. It is read from disk
+// once per shift window and kept current with what is recorded afterwards.
+type handoffData struct {
+	window    time.Time // see handoffWindow
+	records   []historyRecord
+	actions   []auditEntry
+	actionErr string
+}
+
+type handoffLoadedMsg *handoffData
+
+// handoffWindow identifies the shift window; it moves on every hour, which is
+// when the history is read again
+func handoffWindow(now time.Time, hours int) time.Time {
+	return now.Add(-time.Duration(hours) * time.Hour).Truncate(time.Hour)
+}
+
+func loadHandoffCmd(history *historyStore, window time.Time) tea.Cmd {
+	return func() tea.Msg {
+		data := &handoffData{window: window, records: history.load(time.Now().Add(-history.retention))}
+		actions, err := loadAuditLog(window)
+		data.actions = actions
+		if err != nil {
+			data.actionErr = err.Error()
+		}
+		return handoffLoadedMsg(data)
+	}
+}
+
+// The in-TUI handoff covers the last HandoffHours with the issues currently
+// listed as still open. It is shown from the cached history and returns the
+// command loading it when the cache is missing or from an earlier window.
+func (m *model) refreshHandoff() tea.Cmd {
+	now := time.Now()
+	window := handoffWindow(now, m.cfg.HandoffHours)
+	if m.handoffData == nil || !m.handoffData.window.Equal(window) {
+		m.setHandoffContent("Loading history...")
+		return loadHandoffCmd(m.history, window)
+	}
+	h := buildHandoff(m.handoffData.records, m.handoffData.actions, m.notes, m.sentryIssues, now.Add(-time.Duration(m.cfg.HandoffHours)*time.Hour), now)
+	h.ActionsErr = m.handoffData.actionErr
+	m.setHandoffContent(h.render(m.handoffMarkdown))
+	return nil
+}
+
+func (m *model) setHandoffContent(text string) {
+	m.handoffText = text
+	header := lipgloss.Height(logViewerHeaderStyle.Render(" "))
+	footer := lipgloss.Height(logViewerFooterStyle.Render(" "))
+	m.handoffViewport = viewport.New(m.width, m.height-header-footer)
+	m.handoffViewport.YPosition = header
+	m.handoffViewport.SetContent(m.handoffText)
+}
+
+func (m model) handoffView() string {
+	format := "plain text"
+	if m.handoffMarkdown {
+		format = "Markdown"
+	}
+	header := logViewerHeaderStyle.Render(fmt.Sprintf("Shift handoff · last %dh · %s", m.cfg.HandoffHours, format))
+	footer := logViewerFooterStyle.Render("f: Markdown/Plain Text | y: Copy | Scroll with arrow keys / mouse wheel | Esc: Back")
+	return lipgloss.JoinVertical(lipgloss.Left, header, m.handoffViewport.View(), footer)
+}
+
+// handoffMain runs the handoff subcommand and exits
+func handoffMain(cfg config, args []string) {
+	if err := runHandoff(cfg, args, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "oncall handoff:", err)
+		os.Exit(2)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// probeRun returns one probe record per minute from start, with status for
+// each of them
+func probeRun(name string, start time.Time, statuses ...string) []historyRecord {
+	var records []historyRecord
+	for i, s := range statuses {
+		records = append(records, historyRecord{Time: start.Add(time.Duration(i) * time.Minute), Kind: "probe", Name: name, Status: s})
+	}
+	return records
+}
+
+func repeat(status string, n int) []string {
+	statuses := make([]string, n)
+	for i := range statuses {
+		statuses[i] = status
+	}
+	return statuses
+}
+
+func TestBuildHandoffOutages(t *testing.T) {
+	since := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	until := since.Add(2 * time.Hour)
+	tests := []struct {
+		name    string
+		records []historyRecord
+		want    []endpointOutages
+	}{
+		{"healthy", probeRun("api", since, repeat("OK", 120)...), nil},
+		{"WARN is not an outage", probeRun("api", since, repeat("WARN", 120)...), nil},
+		{"one outage", probeRun("api", since, append(append(repeat("OK", 10), repeat("FAIL", 10)...), repeat("OK", 100)...)...),
+			[]endpointOutages{{Name: "api", Outages: 1, Downtime: 10 * time.Minute, Longest: 10 * time.Minute}}},
+		{"two outages", probeRun("api", since, append(append(append(repeat("TIMEOUT", 5), repeat("OK", 5)...), repeat("ERROR", 15)...), repeat("OK", 95)...)...),
+			[]endpointOutages{{Name: "api", Outages: 2, Downtime: 20 * time.Minute, Longest: 15 * time.Minute}}},
+		{"outage in progress at the start of the shift", probeRun("api", since.Add(-30*time.Minute), append(repeat("FAIL", 40), repeat("OK", 110)...)...),
+			[]endpointOutages{{Name: "api", Outages: 1, Downtime: 10 * time.Minute, Longest: 10 * time.Minute}}},
+		{"still down", probeRun("api", since.Add(100*time.Minute), repeat("FAIL", 20)...),
+			[]endpointOutages{{Name: "api", Outages: 1, Downtime: 20 * time.Minute, Longest: 20 * time.Minute, StillDown: true}}},
+		{"gap in the probes ends the outage", append(probeRun("api", since, repeat("FAIL", 11)...), probeRun("api", since.Add(time.Hour), repeat("OK", 60)...)...),
+			[]endpointOutages{{Name: "api", Outages: 1, Downtime: 10 * time.Minute, Longest: 10 * time.Minute}}},
+		{"probes stopped while down", probeRun("api", since, repeat("FAIL", 31)...),
+			[]endpointOutages{{Name: "api", Outages: 1, Downtime: 30 * time.Minute, Longest: 30 * time.Minute}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := buildHandoff(tt.records, nil, nil, nil, since, until)
+			if !reflect.DeepEqual(h.Endpoints, tt.want) {
+				t.Errorf("got %+v, want %+v", h.Endpoints, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildHandoffPods(t *testing.T) {
+	since := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	until := since.Add(2 * time.Hour)
+	pod := func(offset time.Duration, scope, name, status string, restarts int) historyRecord {
+		return historyRecord{Time: since.Add(offset), Kind: "pod", Scope: scope, Name: name, Status: status, Restarts: restarts}
+	}
+	baseline := pod(-time.Hour, "prod/default", "api-1", "Running", 2)
+	baseline.Baseline = true
+	records := []historyRecord{
+		baseline,
+		pod(-time.Hour, "staging/default", "api-1", "Running", 7),
+		pod(-time.Minute, "prod/default", "api-1", "Running", 3), // before the shift
+		pod(10*time.Minute, "prod/default", "api-1", "CrashLoopBackOff", 5),
+		pod(20*time.Minute, "prod/default", "api-1", "Running", 6),
+		pod(30*time.Minute, "staging/default", "api-1", "Running", 8),
+		pod(40*time.Minute, "prod/default", "worker-1", "Pending", 0), // new, not in trouble
+		pod(50*time.Minute, "prod/default", "worker-1", "Running", 0),
+		pod(60*time.Minute, "prod/default", "job-1", "Error", 0),
+		pod(70*time.Minute, "prod/default", "job-1", "Deleted", 0),
+		pod(80*time.Minute, "prod/default", "old-1", "Deleted", 0), // deleted without trouble
+	}
+	h := buildHandoff(records, nil, nil, nil, since, until)
+	want := []podTrouble{
+		{Name: "api-1", Restarts: 3, Statuses: []string{"CrashLoopBackOff"}},
+		{Name: "api-1", Restarts: 1},
+		{Name: "job-1", Statuses: []string{"Error"}, Deleted: true},
+	}
+	if !reflect.DeepEqual(h.Pods, want) {
+		t.Errorf("got %+v, want %+v", h.Pods, want)
+	}
+}
+
+func TestBuildHandoffShiftWindow(t *testing.T) {
+	since := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	until := since.Add(2 * time.Hour)
+	records := []historyRecord{
+		{Time: since.Add(-time.Minute), Kind: "issue", Name: "API-1", Status: "NEW"},
+		{Time: since.Add(time.Minute), Kind: "issue", Name: "API-2", Status: "REGRESSED"},
+		{Time: since.Add(-time.Minute), Kind: "action", Name: "API-0", Status: "resolve"},
+		{Time: since.Add(time.Hour), Kind: "action", Name: "API-2", Status: "resolve"},
+		{Time: since.Add(time.Hour), Kind: "action", Name: "api-1", Status: "restart"},
+	}
+	notes := []incidentNote{{Time: since.Add(-time.Hour), Text: "old"}, {Time: since.Add(time.Hour), Text: "in shift"}, {Time: until.Add(time.Minute), Text: "later"}}
+	actions := []auditEntry{{Time: since.Add(-time.Hour), Action: "delete"}, {Time: since.Add(time.Hour), Action: "restart"}}
+	h := buildHandoff(records, actions, notes, nil, since, until)
+	if len(h.Opened) != 1 || h.Opened[0].Name != "API-2" {
+		t.Errorf("opened %+v, want API-2", h.Opened)
+	}
+	if len(h.Resolved) != 1 || h.Resolved[0].Name != "API-2" {
+		t.Errorf("resolved %+v, want API-2", h.Resolved)
+	}
+	if len(h.Notes) != 1 || h.Notes[0].Text != "in shift" {
+		t.Errorf("notes %+v, want the one in the shift", h.Notes)
+	}
+	if len(h.Actions) != 1 || h.Actions[0].Action != "restart" {
+		t.Errorf("actions %+v, want the restart", h.Actions)
+	}
+}
@@ -1,199 +1,265 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os/exec"
-	"strconv"
-	"strings"
+	"io"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-type apiResponseTimesMsg string
+type endpointResult struct {
+	Name       string
+	URL        string
+	Status     string // OK, WARN, FAIL, ERROR or TIMEOUT
+	Latency    time.Duration
+	Err        string
+	Detail     string   // probe specific summary, e.g. resolved records
+	Failures   []string // failed assertions
+	TLS        *tlsInfo // nil for plain HTTP
+	Components []*healthNode
+}
+
+type apiResponseTimesMsg []endpointResult
+
+type probeResponse struct {
+	statusCode int
+	body       []byte
+	latency    time.Duration
+	tls        *tlsInfo
+}
 
-func getApiResponseTimesCmd() tea.Cmd {
+// Probe all checks concurrently; results keep the configured order
+func getApiResponseTimesCmd(checks []healthCheckConfig, timeoutMS int) tea.Cmd {
 	return func() tea.Msg {
-		apiEndpoints := map[string]string{
-			"Ticketing API": "https://ticketing.siip.io/health",
-			"IAM API":       "https://iam.siip.io/health",
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutMS)*time.Millisecond)
+		defer cancel()
+		results := make([]endpointResult, len(checks))
+		var wg sync.WaitGroup
+		for i, check := range checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = runHealthCheck(ctx, check)
+			}()
 		}
+		wg.Wait()
+		return apiResponseTimesMsg(results)
+	}
+}
 
-		var results []string
-		for name, url := range apiEndpoints {
-			// Capture body separately, then measure time
-			bodyCmd := exec.Command("curl", "-s", url)
-			bodyBytes, bodyErr := bodyCmd.CombinedOutput()
-			if bodyErr != nil {
-				results = append(results, fmt.Sprintf("%s: Error - %v", name, bodyErr))
-				continue
-			}
-			body := strings.TrimSpace(string(bodyBytes))
+// probeHTTP uses a fresh connection per probe so latency includes the
+// handshake and the presented certificate chain can be recorded. The TLS
+// info is returned even when the request fails. Requests are bounded by ctx.
+func probeHTTP(ctx context.Context, url string, auth *probeAuth) (probeResponse, error) {
+	result := probeResponse{}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return result, err
+	}
+	auth.apply(req)
+	info := &tlsInfo{}
+	if req.URL.Scheme == "https" {
+		result.tls = info
+	}
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:             http.ProxyFromEnvironment,
+		DisableKeepAlives: true,
+		TLSClientConfig:   probeTLSConfig(info, req.URL.Hostname(), auth),
+	}}
+	start := time.Now()
+	resp, err := client.Do(req)
+	result.latency = time.Since(start)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+	result.body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return result, err
+	}
+	result.statusCode = resp.StatusCode
+	result.latency = time.Since(start)
+	return result, nil
+}
 
-			timeCmd := exec.Command("curl", "-s", "-o", "/dev/null", "-w", "%{time_total}", url)
-			timeBytes, timeErr := timeCmd.CombinedOutput()
-			if timeErr != nil {
-				results = append(results, fmt.Sprintf("%s: Error - %v", name, timeErr))
-				continue
-			}
-			secStr := strings.TrimSpace(string(timeBytes))
-			sec, _ := strconv.ParseFloat(secStr, 64)
-			ms := int(sec * 1000.0)
+func runHealthCheck(ctx context.Context, check healthCheckConfig) endpointResult {
+	auth, err := resolveProbeAuth(check)
+	if err != nil {
+		return endpointResult{Name: check.Name, URL: check.target(), Status: "ERROR", Err: err.Error()}
+	}
+	result := probeCheck(ctx, check, auth)
+	result.Err = auth.redact(result.Err)
+	result.Detail = auth.redact(result.Detail)
+	for i := range result.Failures {
+		result.Failures[i] = auth.redact(result.Failures[i])
+	}
+	var redactNodes func(nodes []*healthNode)
+	redactNodes = func(nodes []*healthNode) {
+		for _, node := range nodes {
+			node.Err = auth.redact(node.Err)
+			redactNodes(node.Children)
+		}
+	}
+	redactNodes(result.Components)
+	return result
+}
 
-			formatted := fmt.Sprintf("%s: %dms", name, ms)
+func probeCheck(ctx context.Context, check healthCheckConfig, auth *probeAuth) endpointResult {
+	result := endpointResult{Name: check.Name, URL: check.target()}
+	checkCtx, cancelCheck := context.WithTimeout(ctx, time.Duration(check.TimeoutMS)*time.Millisecond)
+	defer cancelCheck()
+	if check.Type != "" && check.Type != "http" {
+		start := time.Now()
+		detail, failures, err := runNetworkProbe(checkCtx, check, auth)
+		result.Latency = time.Since(start)
+		if err != nil {
+			setProbeError(&result, ctx, checkCtx, check, err)
+			return result
+		}
+		result.Detail = detail
+		result.Failures = failures
+		if f := checkLatency(check, result.Latency); f != "" {
+			result.Failures = append(result.Failures, f)
+		}
+		result.Status = "OK"
+		if len(result.Failures) > 0 {
+			result.Status = "FAIL"
+		}
+		return result
+	}
+	resp, err := probeHTTP(checkCtx, check.URL, auth)
+	result.TLS = resp.tls
+	result.Latency = resp.latency
+	if err != nil && resp.tls != nil && resp.tls.VerifyErr != "" {
+		// the certificate was rejected; report it like a failed assertion
+		result.Status = "FAIL"
+		result.Failures = checkTLS(check, resp.tls)
+		return result
+	}
+	if err != nil {
+		setProbeError(&result, ctx, checkCtx, check, err)
+		return result
+	}
+	result.Failures = append(checkAssertions(check, resp), checkTLS(check, resp.tls)...)
+	result.Status = "OK"
+	if len(result.Failures) > 0 {
+		result.Status = "FAIL"
+	} else if resp.tls != nil && resp.tls.Level == "warn" {
+		result.Status = "WARN"
+	}
+	treeCtx, cancelTree := context.WithTimeout(ctx, healthTreeTimeout)
+	defer cancelTree()
+	result.Components = discoverHealthChildren(treeCtx, check.URL, resp.body, 0, auth)
+	return result
+}
 
-			if name == "Ticketing API" {
-				// Robust status detection without JSON lib
-				status := extractJsonValue(body, "status")
-				lower := strings.ToLower(body)
-				okDetected := strings.EqualFold(status, "ok") || strings.EqualFold(status, "up") || strings.Contains(lower, `"status":"ok"`) || strings.Contains(lower, `"status":"up"`)
-				failDetected := strings.EqualFold(status, "fail") || strings.EqualFold(status, "down") || strings.Contains(lower, `"status":"fail"`) || strings.Contains(lower, `"status":"down"`)
-				statusText := "unknown"
-				statusStyle := defaultStyle
-				if okDetected {
-					statusText = "OK"
-					statusStyle = statusResolvedStyle
-				} else if failDetected || status != "" {
-					statusText = "FAIL"
-					statusStyle = statusUnresolvedStyle
-				}
-				formatted += "\n  General: " + statusStyle.Render(statusText)
-			} else if name == "IAM API" {
-				// Overall status
-				status := extractJsonValue(body, "status")
-				if status != "" {
-					statusStyle := defaultStyle
-					if strings.EqualFold(status, "ok") || strings.EqualFold(status, "up") {
-						statusStyle = statusResolvedStyle
-					} else {
-						statusStyle = statusUnresolvedStyle
-					}
-					formatted += "\n  Status: " + statusStyle.Render(strings.ToUpper(status))
-				}
-				// Groups list from root
-				groups := extractJsonValue(body, "groups")
-				var groupNames []string
-				if groups != "" {
-					raw := strings.TrimSpace(groups)
-					raw = strings.TrimPrefix(raw, "[")
-					raw = strings.TrimSuffix(raw, "]")
-					parts := strings.Split(raw, ",")
-					for i := range parts {
-						name := strings.TrimSpace(parts[i])
-						name = strings.Trim(name, `"`)
-						name = strings.Trim(name, "]}")
-						if name != "" {
-							groupNames = append(groupNames, name)
-						}
-					}
-				}
-				if len(groupNames) > 0 {
-					formatted += "\n  Groups: " + levelInfoStyle.Render(strings.Join(groupNames, ", "))
-					// Fetch each group endpoint and list its status
-					for _, g := range groupNames {
-						gURL := url + "/" + g
-						gBodyCmd := exec.Command("curl", "-s", gURL)
-						gBodyBytes, _ := gBodyCmd.CombinedOutput()
-						gBody := strings.TrimSpace(string(gBodyBytes))
-						gStatus := extractJsonValue(gBody, "status")
-						gLower := strings.ToLower(gBody)
-						gOK := strings.EqualFold(gStatus, "ok") || strings.EqualFold(gStatus, "up") || strings.Contains(gLower, `"status":"ok"`) || strings.Contains(gLower, `"status":"up"`)
-						gStyle := statusUnresolvedStyle
-						gText := strings.ToUpper(strings.TrimSpace(gStatus))
-						if gOK {
-							gStyle = statusResolvedStyle
-							if gText == "" {
-								gText = "OK"
-							}
-						} else if gText == "" {
-							gText = "FAIL"
-						}
-						formatted += "\n    - " + g + ": " + gStyle.Render(gText)
-					}
-				}
-			}
+// setProbeError tells a probe that timed out, globally or per check, apart
+// from one that failed outright.
+func setProbeError(result *endpointResult, ctx, checkCtx context.Context, check healthCheckConfig, err error) {
+	result.Status = "ERROR"
+	result.Err = err.Error()
+	switch {
+	case ctx.Err() != nil:
+		result.Status = "TIMEOUT"
+		result.Err = "global health timeout reached"
+	case checkCtx.Err() != nil:
+		result.Status = "TIMEOUT"
+		result.Err = fmt.Sprintf("no response within %dms", check.TimeoutMS)
+	}
+}
 
-			results = append(results, formatted)
-		}
-		return apiResponseTimesMsg(strings.Join(results, "\n"))
+func checkLatency(check healthCheckConfig, latency time.Duration) string {
+	if check.MaxLatencyMS > 0 && latency > time.Duration(check.MaxLatencyMS)*time.Millisecond {
+		return fmt.Sprintf("latency %dms exceeds %dms", latency.Milliseconds(), check.MaxLatencyMS)
 	}
+	return ""
 }
 
-// Robust JSON extractor for simple key lookup without using encoding/json.
-// Handles string scalars, numbers, booleans, and balanced []/{} values.
-func extractJsonValue(jsonStr, key string) string {
-	searchKey := fmt.Sprintf(`"%s":`, key)
-	idx := strings.Index(jsonStr, searchKey)
-	if idx == -1 {
-		return ""
-	}
-	i := idx + len(searchKey)
-	// skip spaces
-	for i < len(jsonStr) && (jsonStr[i] == ' ' || jsonStr[i] == '\t' || jsonStr[i] == '\n' || jsonStr[i] == '\r') {
-		i++
-	}
-	if i >= len(jsonStr) {
-		return ""
-	}
-	// String value
-	if jsonStr[i] == '"' {
-		i++
-		start := i
-		for i < len(jsonStr) {
-			if jsonStr[i] == '\\' {
-				i += 2
-				continue
-			}
-			if jsonStr[i] == '"' {
-				return jsonStr[start:i]
+// checkAssertions returns a description of every assertion resp fails
+func checkAssertions(check healthCheckConfig, resp probeResponse) []string {
+	var failures []string
+	if len(check.ExpectStatus) == 0 {
+		if resp.statusCode < 200 || resp.statusCode > 299 {
+			failures = append(failures, fmt.Sprintf("HTTP %d, expected 2xx", resp.statusCode))
+		}
+	} else if !containsInt(check.ExpectStatus, resp.statusCode) {
+		failures = append(failures, fmt.Sprintf("HTTP %d, expected %v", resp.statusCode, check.ExpectStatus))
+	}
+	if f := checkLatency(check, resp.latency); f != "" {
+		failures = append(failures, f)
+	}
+	if check.BodyRegex != "" {
+		re, err := regexp.Compile(check.BodyRegex)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("invalid bodyRegex: %v", err))
+		} else if !re.Match(resp.body) {
+			failures = append(failures, fmt.Sprintf("body does not match /%s/", check.BodyRegex))
+		}
+	}
+	if len(check.JSONPath) == 0 {
+		return failures
+	}
+	var doc any
+	if err := json.Unmarshal(resp.body, &doc); err != nil {
+		for _, a := range check.JSONPath {
+			if !a.Optional {
+				return append(failures, fmt.Sprintf("body is not JSON: %v", err))
 			}
-			i++
 		}
-		return jsonStr[start:]
-	}
-	// Array or object
-	if jsonStr[i] == '[' || jsonStr[i] == '{' {
-		open := jsonStr[i]
-		close := byte(']')
-		if open == '{' {
-			close = '}'
+		return failures
+	}
+	for _, a := range check.JSONPath {
+		if f := checkJSONPathAssertion(doc, a); f != "" {
+			failures = append(failures, f)
 		}
-		depth := 0
-		start := i
-		for i < len(jsonStr) {
-			c := jsonStr[i]
-			if c == '"' {
-				// skip quoted sections
-				i++
-				for i < len(jsonStr) {
-					if jsonStr[i] == '\\' {
-						i += 2
-						continue
-					}
-					if jsonStr[i] == '"' {
-						break
-					}
-					i++
-				}
-			} else {
-				if c == open {
-					depth++
-				}
-				if c == close {
-					depth--
-				}
-				if depth == 0 {
-					// include closing bracket and stop
-					return strings.TrimSpace(jsonStr[start : i+1])
-				}
+	}
+	return failures
+}
+
+func checkJSONPathAssertion(doc any, a jsonPathAssertion) string {
+	value, err := lookupJSONPath(doc, a.Path)
+	if err != nil {
+		// only a missing value is optional, an invalid path is always reported
+		if a.Optional && errors.Is(err, errJSONPathMissing) {
+			return ""
+		}
+		return err.Error()
+	}
+	if a.Equals != nil && !jsonValuesEqual(value, a.Equals) {
+		return fmt.Sprintf("%s = %s, expected %s", a.Path, compactJSON(value), compactJSON(a.Equals))
+	}
+	if len(a.OneOf) > 0 {
+		for _, want := range a.OneOf {
+			if jsonValuesEqual(value, want) {
+				return ""
 			}
-			i++
 		}
-		return strings.TrimSpace(jsonStr[start:])
+		return fmt.Sprintf("%s = %s, expected one of %s", a.Path, compactJSON(value), compactJSON(a.OneOf))
 	}
-	// Number/boolean/null: read until comma or '}'
-	start := i
-	for i < len(jsonStr) && jsonStr[i] != ',' && jsonStr[i] != '}' && jsonStr[i] != '\n' && jsonStr[i] != '\r' {
-		i++
+	return ""
+}
+
+func compactJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(bytes.TrimSpace(b))
+}
+
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
 	}
-	return strings.TrimSpace(jsonStr[start:i])
+	return false
 }
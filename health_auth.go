@@ -0,0 +1,183 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// probeAuth holds the resolved credentials of a check. It is built fresh for
+// every probe round so rotated tokens and certificates are picked up, and is
+// never rendered; redact scrubs its secrets from anything that is.
+type probeAuth struct {
+	header  http.Header
+	cert    *tls.Certificate
+	roots   *x509.CertPool
+	secrets []string
+
+	// basicAuth credentials, also used for the Redis AUTH command
+	username, password string
+}
+
+// resolveSecret reads a secret reference: "env:NAME" or "file:/path".
+// Errors name the reference, never the value.
+func resolveSecret(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, "env:"):
+		name := strings.TrimPrefix(ref, "env:")
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return v, nil
+	case strings.HasPrefix(ref, "file:"):
+		path := strings.TrimPrefix(ref, "file:")
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, rest)
+			}
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file %s", path)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	return "", errors.New("must be an env: or file: reference")
+}
+
+func isSecretRef(v string) bool {
+	return strings.HasPrefix(v, "env:") || strings.HasPrefix(v, "file:")
+}
+
+// Headers that carry credentials, in canonical form
+var credentialHeaders = []string{
+	"Authorization", "Proxy-Authorization", "Cookie",
+	"X-Api-Key", "Api-Key", "Apikey", "X-Auth-Token", "X-Access-Token", "Private-Token", "X-Vault-Token",
+}
+
+// isCredentialHeader reports whether a header's value must be a secret
+// reference rather than written into the config
+func isCredentialHeader(name string) bool {
+	return slices.Contains(credentialHeaders, http.CanonicalHeaderKey(name))
+}
+
+// systemCertPool is replaced in tests
+var systemCertPool = x509.SystemCertPool
+
+// resolveProbeAuth returns nil when the check has no credentials configured
+func resolveProbeAuth(check healthCheckConfig) (*probeAuth, error) {
+	if len(check.Headers) == 0 && check.BearerToken == "" && check.BasicAuth == nil && check.ClientCert == "" && check.CACert == "" {
+		return nil, nil
+	}
+	auth := &probeAuth{header: http.Header{}}
+	secret := func(field, ref string) (string, error) {
+		v, err := resolveSecret(ref)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", field, err)
+		}
+		auth.secrets = append(auth.secrets, v)
+		return v, nil
+	}
+
+	for name, value := range check.Headers {
+		if !isSecretRef(value) && isCredentialHeader(name) {
+			return nil, fmt.Errorf("header %s: credentials must be an env: or file: reference", name)
+		}
+		if isSecretRef(value) {
+			v, err := secret("header "+name, value)
+			if err != nil {
+				return nil, err
+			}
+			value = v
+		}
+		auth.header.Set(name, value)
+	}
+	switch {
+	case check.BearerToken != "" && check.BasicAuth != nil:
+		return nil, errors.New("bearerToken and basicAuth are mutually exclusive")
+	case check.BearerToken != "":
+		token, err := secret("bearerToken", check.BearerToken)
+		if err != nil {
+			return nil, err
+		}
+		auth.header.Set("Authorization", "Bearer "+token)
+	case check.BasicAuth != nil:
+		username := check.BasicAuth.Username
+		if isSecretRef(username) {
+			v, err := secret("basicAuth.username", username)
+			if err != nil {
+				return nil, err
+			}
+			username = v
+		}
+		password, err := secret("basicAuth.password", check.BasicAuth.Password)
+		if err != nil {
+			return nil, err
+		}
+		auth.username, auth.password = username, password
+		encoded := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		auth.secrets = append(auth.secrets, encoded)
+		auth.header.Set("Authorization", "Basic "+encoded)
+	}
+
+	if check.ClientCert != "" || check.ClientKey != "" {
+		certPEM, err := secret("clientCert", check.ClientCert)
+		if err != nil {
+			return nil, err
+		}
+		keyPEM, err := secret("clientKey", check.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+		if err != nil {
+			return nil, errors.New("clientCert/clientKey: not a valid PEM certificate and key pair")
+		}
+		auth.cert = &cert
+	}
+	if check.CACert != "" {
+		caPEM, err := resolveSecret(check.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("caCert: %w", err)
+		}
+		// caCert adds to the system roots, so public CAs stay trusted
+		auth.roots, err = systemCertPool()
+		if err != nil {
+			auth.roots = x509.NewCertPool()
+		}
+		if !auth.roots.AppendCertsFromPEM([]byte(caPEM)) {
+			return nil, errors.New("caCert: no PEM certificates found")
+		}
+	}
+	return auth, nil
+}
+
+func (a *probeAuth) apply(req *http.Request) {
+	if a == nil {
+		return
+	}
+	for name, values := range a.header {
+		req.Header[name] = values
+	}
+}
+
+// redact replaces every resolved secret in s
+func (a *probeAuth) redact(s string) string {
+	if a == nil {
+		return s
+	}
+	for _, secret := range a.secrets {
+		if len(secret) >= 4 {
+			s = strings.ReplaceAll(s, secret, "[redacted]")
+		}
+	}
+	return s
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCACertKeepsSystemRoots(t *testing.T) {
+	public, internal := newTestCA(t), newTestCA(t)
+	saved := systemCertPool
+	systemCertPool = func() (*x509.CertPool, error) { return public.pool.Clone(), nil }
+	t.Cleanup(func() { systemCertPool = saved })
+
+	caFile := filepath.Join(t.TempDir(), "internal-ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: internal.cert.Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for name, ca := range map[string]*testCA{"public CA": public, "internal CA": internal} {
+		t.Run(name, func(t *testing.T) {
+			check := healthCheckConfig{Name: name, URL: ca.server(t, "127.0.0.1", time.Now().Add(60*24*time.Hour)), CACert: "file:" + caFile, TimeoutMS: 5000, TLSWarnDays: 21, TLSCriticalDays: 7}
+			auth, err := resolveProbeAuth(check)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result := probeCheck(testContext(t), check, auth); result.Status != "OK" {
+				t.Errorf("got %s %v %s, want OK", result.Status, result.Failures, result.Err)
+			}
+		})
+	}
+}
+
+func TestCredentialHeaders(t *testing.T) {
+	t.Setenv("ONCALL_TEST_TOKEN", "s3cret-token")
+	tests := []struct {
+		name, value string
+		wantErr     bool
+	}{
+		{"Authorization", "Bearer s3cret", true},
+		{"authorization", "Bearer s3cret", true},
+		{"X-Api-Key", "s3cret", true},
+		{"X-Auth-Token", "s3cret", true},
+		{"Authorization", "env:ONCALL_TEST_TOKEN", false},
+		{"X-Author", "ops", false},
+		{"X-Tenant", "siip", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"="+tt.value, func(t *testing.T) {
+			_, err := resolveProbeAuth(healthCheckConfig{Headers: map[string]string{tt.name: tt.value}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), tt.value) {
+				t.Errorf("error %q reveals the value", err)
+			}
+		})
+	}
+}
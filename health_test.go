@@ -0,0 +1,152 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// healthStandIn answers with body after delay, or gives up when the client
+// does
+func healthStandIn(t *testing.T, delay time.Duration, status int, body string) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// refusedURL is an address nothing listens on
+func refusedURL(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return "http://" + addr + "/health"
+}
+
+func runProbeRound(checks []healthCheckConfig, timeoutMS int) []endpointResult {
+	return getApiResponseTimesCmd(checks, timeoutMS)().(apiResponseTimesMsg)
+}
+
+func TestProbeRoundTimeouts(t *testing.T) {
+	slow := healthStandIn(t, 2*time.Second, http.StatusOK, `{"status":"ok"}`)
+	fast := healthStandIn(t, 0, http.StatusOK, `{"status":"ok"}`)
+	tests := []struct {
+		name      string
+		checkMS   int // per-check timeout of the slow check
+		roundMS   int
+		want      []string // statuses of slow, fast and refused
+		slowError string
+	}{
+		{"per-check timeout", 100, 1000, []string{"TIMEOUT", "OK", "ERROR"}, "no response within 100ms"},
+		{"round timeout", 5000, 300, []string{"TIMEOUT", "OK", "ERROR"}, "global health timeout reached"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := []healthCheckConfig{
+				{Name: "slow", URL: slow, TimeoutMS: tt.checkMS},
+				{Name: "fast", URL: fast, TimeoutMS: 1000},
+				{Name: "refused", URL: refusedURL(t), TimeoutMS: 1000},
+			}
+			start := time.Now()
+			results := runProbeRound(checks, tt.roundMS)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("round took %s, the slow check was not cut short", elapsed)
+			}
+			for i, r := range results {
+				if r.Name != checks[i].Name || r.Status != tt.want[i] {
+					t.Errorf("result %d: got %s %s (%s), want %s %s", i, r.Name, r.Status, r.Err, checks[i].Name, tt.want[i])
+				}
+			}
+			if results[0].Err != tt.slowError {
+				t.Errorf("slow check error %q, want %q", results[0].Err, tt.slowError)
+			}
+		})
+	}
+}
+
+func TestProbeRoundIsConcurrentAndOrdered(t *testing.T) {
+	var checks []healthCheckConfig
+	for i, delay := range []time.Duration{300, 50, 200, 0} {
+		checks = append(checks, healthCheckConfig{
+			Name:      strings.Repeat("x", i+1),
+			URL:       healthStandIn(t, delay*time.Millisecond, http.StatusOK, `{"status":"ok"}`),
+			TimeoutMS: 2000,
+		})
+	}
+	start := time.Now()
+	results := runProbeRound(checks, 3000)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("round took %s, want about as long as the slowest check", elapsed)
+	}
+	for i, r := range results {
+		if r.Name != checks[i].Name || r.Status != "OK" {
+			t.Errorf("result %d: got %s %s (%s), want %s OK", i, r.Name, r.Status, r.Err, checks[i].Name)
+		}
+	}
+}
+
+func TestHTTPAssertions(t *testing.T) {
+	const body = `{"status":"UP","version":"1.4.2","replicas":3,"db":{"status":"DOWN"}}`
+	tests := []struct {
+		name     string
+		status   int
+		delay    time.Duration
+		body     string
+		check    healthCheckConfig
+		want     string
+		failures []string
+	}{
+		{"any 2xx by default", http.StatusNoContent, 0, body, healthCheckConfig{}, "OK", nil},
+		{"5xx by default", http.StatusServiceUnavailable, 0, body, healthCheckConfig{}, "FAIL", []string{"HTTP 503, expected 2xx"}},
+		{"expectStatus accepts", http.StatusUnauthorized, 0, body, healthCheckConfig{ExpectStatus: []int{401, 403}}, "OK", nil},
+		{"expectStatus rejects", http.StatusOK, 0, body, healthCheckConfig{ExpectStatus: []int{204}}, "FAIL", []string{"HTTP 200, expected [204]"}},
+		{"within maxLatencyMs", http.StatusOK, 0, body, healthCheckConfig{MaxLatencyMS: 1000}, "OK", nil},
+		{"over maxLatencyMs", http.StatusOK, 150 * time.Millisecond, body, healthCheckConfig{MaxLatencyMS: 50}, "FAIL", []string{"exceeds 50ms"}},
+		{"bodyRegex matches", http.StatusOK, 0, body, healthCheckConfig{BodyRegex: `"version":"1\.\d+`}, "OK", nil},
+		{"bodyRegex does not match", http.StatusOK, 0, body, healthCheckConfig{BodyRegex: `"version":"2\.`}, "FAIL", []string{`body does not match /"version":"2\./`}},
+		{"invalid bodyRegex", http.StatusOK, 0, body, healthCheckConfig{BodyRegex: `(`}, "FAIL", []string{"invalid bodyRegex"}},
+		{"equals string", http.StatusOK, 0, body, healthCheckConfig{JSONPath: []jsonPathAssertion{{Path: "$.status", Equals: "UP"}}}, "OK", nil},
+		{"equals number", http.StatusOK, 0, body, healthCheckConfig{JSONPath: []jsonPathAssertion{{Path: "$.replicas", Equals: 3}}}, "OK", nil},
+		{"equals mismatch", http.StatusOK, 0, body, healthCheckConfig{JSONPath: []jsonPathAssertion{{Path: "$.db.status", Equals: "UP"}}}, "FAIL", []string{`$.db.status = "DOWN", expected "UP"`}},
+		{"oneOf matches", http.StatusOK, 0, body, healthCheckConfig{JSONPath: []jsonPathAssertion{{Path: "$.status", OneOf: []any{"OK", "UP"}}}}, "OK", nil},
+		{"oneOf mismatch", http.StatusOK, 0, body, healthCheckConfig{JSONPath: []jsonPathAssertion{{Path: "$.db.status", OneOf: []any{"UP", "OK"}}}}, "FAIL", []string{`$.db.status = "DOWN", expected one of ["UP","OK"]`}},
+		{"path only has to exist", http.StatusOK, 0, body, healthCheckConfig{JSONPath: []jsonPathAssertion{{Path: "$.version"}}}, "OK", nil},
+		{"missing path", http.StatusOK, 0, body, healthCheckConfig{JSONPath: []jsonPathAssertion{{Path: "$.uptime"}}}, "FAIL", []string{`key "uptime" not found`}},
+		{"optional missing path", http.StatusOK, 0, body, healthCheckConfig{JSONPath: []jsonPathAssertion{{Path: "$.uptime", Equals: 1, Optional: true}}}, "OK", nil},
+		{"optional path is still checked", http.StatusOK, 0, body, healthCheckConfig{JSONPath: []jsonPathAssertion{{Path: "$.db.status", OneOf: []any{"UP"}, Optional: true}}}, "FAIL", []string{"expected one of"}},
+		{"optional invalid path", http.StatusOK, 0, body, healthCheckConfig{JSONPath: []jsonPathAssertion{{Path: "status", Optional: true}}}, "FAIL", []string{"must start with $"}},
+		{"non-JSON body with optional paths", http.StatusOK, 0, "healthy", healthCheckConfig{JSONPath: defaultJSONPathAssertions()}, "OK", nil},
+		{"non-JSON body", http.StatusOK, 0, "healthy", healthCheckConfig{JSONPath: []jsonPathAssertion{{Path: "$.status"}}}, "FAIL", []string{"body is not JSON"}},
+		{"every failure is reported", http.StatusBadGateway, 0, body, healthCheckConfig{BodyRegex: "^ok$", JSONPath: []jsonPathAssertion{{Path: "$.status", Equals: "ok"}}}, "FAIL", []string{"HTTP 502", "body does not match", "$.status"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := tt.check
+			check.Name, check.URL, check.TimeoutMS = tt.name, healthStandIn(t, tt.delay, tt.status, tt.body), 2000
+			result := probeCheck(testContext(t), check, nil)
+			if result.Status != tt.want || len(result.Failures) != len(tt.failures) {
+				t.Fatalf("got %s %q (%s), want %s %q", result.Status, result.Failures, result.Err, tt.want, tt.failures)
+			}
+			for i, f := range tt.failures {
+				if !strings.Contains(result.Failures[i], f) {
+					t.Errorf("failure %d is %q, want it to contain %q", i, result.Failures[i], f)
+				}
+			}
+		})
+	}
+}
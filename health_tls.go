@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// tlsInfo describes the certificate chain presented during a probe
+type tlsInfo struct {
+	Issuer     string
+	DNSNames   []string
+	ChainLen   int
+	NotAfter   time.Time // earliest expiry in the chain
+	ExpiringCN string    // subject of the certificate expiring first
+	HostErr    string    // SAN/hostname mismatch
+	VerifyErr  string    // chain verification failure, including HostErr
+	Expired    bool      // VerifyErr is about the validity period
+	Level      string    // ok, warn or critical, set by checkTLS
+}
+
+func (t *tlsInfo) daysLeft() int {
+	return int(math.Floor(time.Until(t.NotAfter).Hours() / 24))
+}
+
+// probeTLSConfig verifies the chain itself instead of letting crypto/tls do
+// it, so the chain can be recorded even when verification fails. The
+// connection is still rejected in that case. auth adds the client certificate
+// and extra roots of the check.
+func probeTLSConfig(info *tlsInfo, host string, auth *probeAuth) *tls.Config {
+	var roots *x509.CertPool
+	var certs []tls.Certificate
+	if auth != nil {
+		roots = auth.roots
+		if auth.cert != nil {
+			certs = []tls.Certificate{*auth.cert}
+		}
+	}
+	return &tls.Config{
+		Certificates:       certs,
+		InsecureSkipVerify: true, // verified in VerifyConnection below
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			certs := cs.PeerCertificates
+			if len(certs) == 0 {
+				info.VerifyErr = "no certificate presented"
+				return errors.New(info.VerifyErr)
+			}
+			leaf := certs[0]
+			info.Issuer = certName(leaf.Issuer.CommonName, leaf.Issuer.Organization)
+			info.DNSNames = append([]string{}, leaf.DNSNames...)
+			for _, ip := range leaf.IPAddresses {
+				info.DNSNames = append(info.DNSNames, ip.String())
+			}
+			info.ChainLen = len(certs)
+			earliest := leaf
+			intermediates := x509.NewCertPool()
+			for _, c := range certs[1:] {
+				intermediates.AddCert(c)
+				if c.NotAfter.Before(earliest.NotAfter) {
+					earliest = c
+				}
+			}
+			info.NotAfter = earliest.NotAfter
+			info.ExpiringCN = certName(earliest.Subject.CommonName, earliest.Subject.Organization)
+			if err := leaf.VerifyHostname(host); err != nil {
+				info.HostErr = err.Error()
+			}
+			_, err := leaf.Verify(x509.VerifyOptions{DNSName: host, Intermediates: intermediates, Roots: roots})
+			if err != nil {
+				info.VerifyErr = err.Error()
+				var invalid x509.CertificateInvalidError
+				info.Expired = errors.As(err, &invalid) && invalid.Reason == x509.Expired
+			}
+			return err
+		},
+	}
+}
+
+func certName(cn string, org []string) string {
+	if cn != "" {
+		return cn
+	}
+	return strings.Join(org, ", ")
+}
+
+// checkTLS grades the chain against the thresholds of check and returns a
+// failure for every critical finding.
+func checkTLS(check healthCheckConfig, info *tlsInfo) []string {
+	if info == nil || info.NotAfter.IsZero() {
+		return nil
+	}
+	var failures []string
+	info.Level = "ok"
+	days := info.daysLeft()
+	if info.VerifyErr != "" {
+		info.Level = "critical"
+		// an expired chain is reported by the expiry check below
+		if !info.Expired || days >= 0 {
+			failures = append(failures, "TLS: "+info.VerifyErr)
+		}
+	}
+	switch {
+	case days < 0:
+		info.Level = "critical"
+		failures = append(failures, fmt.Sprintf("certificate %s expired %d days ago", info.ExpiringCN, -days))
+	case days < check.TLSCriticalDays:
+		info.Level = "critical"
+		failures = append(failures, fmt.Sprintf("certificate %s expires in %d days", info.ExpiringCN, days))
+	case days < check.TLSWarnDays && info.Level == "ok":
+		info.Level = "warn"
+	}
+	return failures
+}
+
+// One-line summary plus SAN warning
+func renderTLSInfo(info *tlsInfo) []string {
+	if info == nil || info.NotAfter.IsZero() {
+		return nil
+	}
+	style := statusResolvedStyle
+	switch info.Level {
+	case "critical":
+		style = statusUnresolvedStyle
+	case "warn":
+		style = pendingStyle
+	}
+	lines := []string{fmt.Sprintf("  TLS: %s · issuer %s · chain of %d", style.Render(fmt.Sprintf("%d days left", info.daysLeft())), info.Issuer, info.ChainLen)}
+	if info.HostErr != "" {
+		lines = append(lines, "    "+pendingStyle.Render("⚠ SAN mismatch, certificate covers "+strings.Join(info.DNSNames, ", ")))
+	}
+	return lines
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA issues short-lived server certificates for TLS stand-ins
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// server starts an HTTPS server whose certificate is valid until notAfter
+// for the given IP address or DNS name
+func (ca *testCA) server(t *testing.T, san string, notAfter time.Time) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "test server"},
+		NotBefore:    notAfter.Add(-100 * 24 * time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := net.ParseIP(san); ip != nil {
+		tmpl.IPAddresses = []net.IP{ip}
+	} else {
+		tmpl.DNSNames = []string{san}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0) // rejected handshakes
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestTLSCertificateChecks(t *testing.T) {
+	ca := newTestCA(t)
+	day := 24 * time.Hour
+	tests := []struct {
+		name     string
+		san      string
+		valid    time.Duration
+		trusted  bool
+		status   string
+		level    string
+		contains string // in the failures
+		excludes string
+	}{
+		{"valid", "127.0.0.1", 60 * day, true, "OK", "ok", "", ""},
+		{"warn threshold", "127.0.0.1", 10 * day, true, "WARN", "warn", "", ""},
+		{"critical threshold", "127.0.0.1", 3*day + time.Hour, true, "FAIL", "critical", "expires in 3 days", ""},
+		{"expired", "127.0.0.1", -2*day - time.Hour, true, "FAIL", "critical", "expired 3 days ago", "unknown authority"},
+		{"SAN mismatch", "other.example", 60 * day, true, "FAIL", "critical", "cannot validate certificate for 127.0.0.1", "expire"},
+		{"unknown CA", "127.0.0.1", 60 * day, false, "FAIL", "critical", "unknown authority", "expire"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := ca.server(t, tt.san, time.Now().Add(tt.valid))
+			auth := &probeAuth{}
+			if tt.trusted {
+				auth.roots = ca.pool
+			}
+			check := healthCheckConfig{Name: tt.name, URL: url, TimeoutMS: 5000, TLSWarnDays: 21, TLSCriticalDays: 7}
+			result := probeCheck(testContext(t), check, auth)
+			if result.Status != tt.status || result.TLS == nil || result.TLS.Level != tt.level {
+				t.Fatalf("got %s %+v (%s), want %s level %s", result.Status, result.TLS, result.Err, tt.status, tt.level)
+			}
+			failures := strings.Join(result.Failures, "\n")
+			if tt.contains == "" && failures != "" {
+				t.Errorf("unexpected failures: %s", failures)
+			}
+			if !strings.Contains(failures, tt.contains) || (tt.excludes != "" && strings.Contains(failures, tt.excludes)) {
+				t.Errorf("failures %q should contain %q and not %q", failures, tt.contains, tt.excludes)
+			}
+			if tt.name == "SAN mismatch" && !strings.Contains(strings.Join(renderTLSInfo(result.TLS), "\n"), "SAN mismatch, certificate covers other.example") {
+				t.Errorf("SAN mismatch not shown: %+v", result.TLS)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Deepest level of groups/components followed below an endpoint
+	maxHealthTreeDepth = 4
+	// Budget for discovering all children of one endpoint
+	healthTreeTimeout = 5 * time.Second
+)
+
+// healthNode is a group or component of a health endpoint. Groups are
+// separate URLs (<parent>/<group>) and are fetched; components are usually
+// inline and only fetched when the parent omits their status.
+type healthNode struct {
+	Name     string
+	URL      string // empty for inline components
+	Status   string
+	Healthy  bool
+	Latency  time.Duration
+	Err      string
+	Children []*healthNode
+}
+
+// Spring Boot Actuator style health document
+type healthDoc struct {
+	Status     string                     `json:"status"`
+	Groups     []string                   `json:"groups"`
+	Components map[string]json.RawMessage `json:"components"`
+}
+
+func isHealthyStatus(status string) bool {
+	return strings.EqualFold(status, "ok") || strings.EqualFold(status, "up")
+}
+
+// discoverHealthChildren returns the groups and components referenced by
+// body, fetching linked documents in parallel until ctx expires. Fetches use
+// the credentials of the endpoint.
+func discoverHealthChildren(ctx context.Context, url string, body []byte, depth int, auth *probeAuth) []*healthNode {
+	if depth >= maxHealthTreeDepth {
+		return nil
+	}
+	var doc healthDoc
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil
+	}
+
+	var nodes []*healthNode
+	var wg sync.WaitGroup
+	for _, name := range doc.Groups {
+		node := &healthNode{Name: name, URL: url + "/" + name}
+		nodes = append(nodes, node)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fetchHealthNode(ctx, node, depth+1, auth)
+		}()
+	}
+
+	names := make([]string, 0, len(doc.Components))
+	for name := range doc.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		raw := doc.Components[name]
+		var inline healthDoc
+		_ = json.Unmarshal(raw, &inline)
+		node := &healthNode{Name: name}
+		nodes = append(nodes, node)
+		if inline.Status == "" {
+			// details hidden in the parent document; ask the component itself
+			node.URL = url + "/" + name
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				fetchHealthNode(ctx, node, depth+1, auth)
+			}()
+			continue
+		}
+		node.Status = strings.ToUpper(inline.Status)
+		node.Healthy = isHealthyStatus(inline.Status)
+		node.Children = discoverHealthChildren(ctx, url+"/"+name, raw, depth+1, auth)
+	}
+	wg.Wait()
+	return nodes
+}
+
+func fetchHealthNode(ctx context.Context, node *healthNode, depth int, auth *probeAuth) {
+	resp, err := probeHTTP(ctx, node.URL, auth)
+	if err != nil {
+		node.Status = "ERROR"
+		if ctx.Err() != nil {
+			node.Status = "TIMEOUT"
+		}
+		node.Err = err.Error()
+		return
+	}
+	node.Latency = resp.latency
+	var doc healthDoc
+	_ = json.Unmarshal(resp.body, &doc)
+	node.Status = strings.ToUpper(doc.Status)
+	node.Healthy = len(checkAssertions(healthCheckConfig{JSONPath: defaultJSONPathAssertions()}, resp)) == 0
+	if node.Status == "" {
+		node.Status = "FAIL"
+		if node.Healthy {
+			node.Status = "OK"
+		}
+	}
+	node.Children = discoverHealthChildren(ctx, node.URL, resp.body, depth, auth)
+}
+
+// healthRow is one selectable line of the Analytics pane: an endpoint
+// (node == nil) or a node of its tree.
+type healthRow struct {
+	key      string
+	endpoint int
+	node     *healthNode
+	depth    int
+}
+
+// healthRows flattens the visible rows. Endpoints start expanded and nested
+// nodes collapsed; toggled flips that default for a row key.
+func healthRows(results []endpointResult, toggled map[string]bool) []healthRow {
+	var rows []healthRow
+	var walk func(endpoint int, prefix string, nodes []*healthNode, depth int)
+	walk = func(endpoint int, prefix string, nodes []*healthNode, depth int) {
+		for _, node := range nodes {
+			key := prefix + "/" + node.Name
+			rows = append(rows, healthRow{key: key, endpoint: endpoint, node: node, depth: depth})
+			if len(node.Children) > 0 && toggled[key] {
+				walk(endpoint, key, node.Children, depth+1)
+			}
+		}
+	}
+	for i, r := range results {
+		rows = append(rows, healthRow{key: r.Name, endpoint: i})
+		if !toggled[r.Name] {
+			walk(i, r.Name, r.Components, 1)
+		}
+	}
+	return rows
+}
+
+func (r healthRow) expanded(toggled map[string]bool) bool {
+	if r.node == nil {
+		return !toggled[r.key]
+	}
+	return toggled[r.key]
+}
+
+func renderEndpointResults(results []endpointResult, selectedRow int, toggled map[string]bool, slos map[string][]sloStatus, history *probeHistory) string {
+	var lines []string
+	for i, row := range healthRows(results, toggled) {
+		var line string
+		if row.node == nil {
+			r := results[row.endpoint]
+			switch {
+			case r.Status == "TIMEOUT":
+				line = fmt.Sprintf("%s: %s - %s", r.Name, pendingStyle.Render("Timeout"), r.Err)
+			case r.Err != "":
+				line = fmt.Sprintf("%s: Error - %s", r.Name, r.Err)
+			default:
+				line = fmt.Sprintf("%s: %dms", r.Name, r.Latency.Milliseconds())
+			}
+			if latencies := recentLatencies(history.samples[r.Name], time.Now()); len(latencies) > 1 {
+				line += " " + sparkline(latencies, 24)
+			}
+			if i == selectedRow {
+				line = highlightStyle.Render(line)
+			}
+			lines = append(lines, line)
+			for _, s := range slos[r.Name] {
+				lines = append(lines, renderSLOStatus(s))
+			}
+			if r.Err != "" {
+				continue
+			}
+			statusStyle := statusUnresolvedStyle
+			switch r.Status {
+			case "OK":
+				statusStyle = statusResolvedStyle
+			case "WARN":
+				statusStyle = pendingStyle
+			}
+			status := "  Status: " + statusStyle.Render(r.Status)
+			if r.Detail != "" {
+				status += " · " + r.Detail
+			}
+			lines = append(lines, status)
+			lines = append(lines, renderTLSInfo(r.TLS)...)
+			for _, f := range r.Failures {
+				lines = append(lines, "    "+statusUnresolvedStyle.Render("✗ "+f))
+			}
+			continue
+		}
+
+		marker := "•"
+		if len(row.node.Children) > 0 {
+			marker = "▸"
+			if row.expanded(toggled) {
+				marker = "▾"
+			}
+		}
+		name := marker + " " + row.node.Name
+		if i == selectedRow {
+			name = highlightStyle.Render(name)
+		}
+		statusStyle := statusUnresolvedStyle
+		if row.node.Healthy {
+			statusStyle = statusResolvedStyle
+		}
+		line = strings.Repeat("  ", row.depth) + name + ": " + statusStyle.Render(row.node.Status)
+		if row.node.Latency > 0 {
+			line += fmt.Sprintf(" %dms", row.node.Latency.Milliseconds())
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
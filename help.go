@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// keyHelp is one key binding shown in the ? overlay
+type keyHelp struct {
+	key, action string
+}
+
+// helpSections are the key bindings of the main screen, global ones first and
+// then those of each pane in pane order
+var helpSections = []struct {
+	title string
+	keys  []keyHelp
+}{
+	{"Global", []keyHelp{
+		{"Tab/Shift+Tab", "Switch panes"},
+		{"↑/↓ or k/j", "Select"},
+		{"T", "Incident timeline"},
+		{"n", "Notes"},
+		{"H", "Shift handoff"},
+		{"W", "Workloads"},
+		{"F", "Port-forwards"},
+		{"E", "Export incident report"},
+		{"?", "This help"},
+		{"q/^C", "Quit"},
+	}},
+	{"Sentry Errors", []keyHelp{
+		{"R", "Resolve issue"},
+		{"/", "Edit query"},
+		{"0-9", "Default and saved queries"},
+		{"s", "Sort"},
+		{"o/y", "Open/copy issue link"},
+	}},
+	{"Analytics", []keyHelp{
+		{"Enter/Space", "Expand or collapse health components"},
+		{"o/y", "Open/copy endpoint link"},
+	}},
+	{"Pods", []keyHelp{
+		{"l", "Logs"},
+		{"d", "Describe"},
+		{"e", "Shell"},
+		{"p", "Port-forward"},
+		{"D", "Delete pod"},
+		{"R", "Rollout restart"},
+		{"S", "Scale"},
+		{"C", "Cordon node"},
+		{"o/y", "Open/copy pod link"},
+	}},
+	{"Warning Events", []keyHelp{
+		{"Enter/Space", "Go to pod"},
+	}},
+}
+
+// helpColumn places each of helpSections in the left or right column
+var helpColumn = []int{0, 1, 0, 1, 0}
+
+// paneKeyHints are the keys of the focused pane for the hint line
+var paneKeyHints = []string{
+	"R: Resolve | /: Query | 0-9: Saved Queries | s: Sort | o/y: Open/Copy Link",
+	"Enter: Expand | o/y: Open/Copy Link",
+	"l: Logs | d: Describe | e: Shell | p: Port-forward | D/R/S/C: Pod Actions",
+	"Enter: Go to Pod",
+}
+
+// keyHintLine is the short hint line under the panes; ? lists the rest
+func (m model) keyHintLine() string {
+	hint := "q: Quit | ?: Help | Tab: Switch Panes | " + paneKeyHints[m.selectedPane]
+	if n := m.activeForwards(); n > 0 {
+		hint += fmt.Sprintf(" | F: Port-forwards (%d)", n)
+	}
+	return hint
+}
+
+// helpView lists helpSections in two columns so it fits small terminals
+func (m model) helpView() string {
+	var columns [2][]string
+	for i, section := range helpSections {
+		col := &columns[helpColumn[i]]
+		if len(*col) > 0 {
+			*col = append(*col, "")
+		}
+		*col = append(*col, paneTitleStyle.Render(section.title))
+		for _, k := range section.keys {
+			*col = append(*col, fmt.Sprintf("  %-14s %s", k.key, k.action))
+		}
+	}
+	body := lipgloss.JoinHorizontal(lipgloss.Top, strings.Join(columns[0], "\n"), "    ", strings.Join(columns[1], "\n"))
+	return m.modalView([]string{headerStyle.Render("Keys"), "", body, "", "Esc: Close"})
+}
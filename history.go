@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// historyRecord is one line of the history files. Probe results are stored
+// for every refresh; Sentry counts and pod states only when they change.
+// Issue arrivals, deploys and actions taken in the TUI are stored as they
+// happen.
+type historyRecord struct {
+	Time      time.Time `json:"t"`
+	Kind      string    `json:"kind"` // "probe", "sentry", "pod", "issue", "deploy" or "action"
+	Name      string    `json:"name"` // check name, project slug, pod name, issue short ID or Deployment
+	Status    string    `json:"status,omitempty"`
+	From      string    `json:"from,omitempty"` // previous pod status or revision; empty for new pods
+	LatencyMS int64     `json:"latencyMs,omitempty"`
+	Count     int       `json:"count,omitempty"`
+	Restarts  int       `json:"restarts,omitempty"`
+	Title     string    `json:"title,omitempty"`    // issue title or action description
+	Baseline  bool      `json:"baseline,omitempty"` // pods found on the very first refresh
+	Scope     string    `json:"scope,omitempty"`    // kube context/namespace of a deploy or pod
+}
+
+// historyStore appends records to one JSON Lines file per day, so expiring
+// old history is a matter of deleting whole files.
+type historyStore struct {
+	dir       string
+	retention time.Duration
+
+	mu      sync.Mutex // appends run in commands, see writeHistoryCmd
+	current string     // file written last; a new one triggers pruning
+}
+
+func historyDir() string {
+	return filepath.Join(stateDir(), "history")
+}
+
+func openHistoryStore(dir string, retentionDays int) *historyStore {
+	return &historyStore{dir: dir, retention: time.Duration(retentionDays) * 24 * time.Hour}
+}
+
+func (s *historyStore) fileFor(day time.Time) string {
+	return filepath.Join(s.dir, day.Format("2006-01-02")+".jsonl")
+}
+
+func (s *historyStore) append(records []historyRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	path := s.fileFor(records[0].Time)
+	if path != s.current {
+		s.current = path
+		if err := s.prune(records[0].Time); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// load returns all records newer than since in chronological order. Lines
+// that fail to parse (e.g. cut off by a crash) are skipped.
+func (s *historyStore) load(since time.Time) []historyRecord {
+	files, _ := filepath.Glob(filepath.Join(s.dir, "*.jsonl"))
+	sort.Strings(files)
+	var records []historyRecord
+	for _, path := range files {
+		day, err := time.ParseInLocation("2006-01-02", strings.TrimSuffix(filepath.Base(path), ".jsonl"), time.Local)
+		if err != nil || day.AddDate(0, 0, 1).Before(since) {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var r historyRecord
+			if json.Unmarshal(scanner.Bytes(), &r) == nil && !r.Time.Before(since) {
+				records = append(records, r)
+			}
+		}
+		f.Close()
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	return records
+}
+
+// prune deletes the files of days entirely outside the retention period
+func (s *historyStore) prune(now time.Time) error {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.jsonl"))
+	if err != nil {
+		return err
+	}
+	cutoff := now.Add(-s.retention)
+	for _, path := range files {
+		day, err := time.ParseInLocation("2006-01-02", strings.TrimSuffix(filepath.Base(path), ".jsonl"), time.Local)
+		if err == nil && day.AddDate(0, 0, 1).Before(cutoff) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func probeRecords(results []endpointResult, now time.Time) []historyRecord {
+	records := make([]historyRecord, 0, len(results))
+	for _, r := range results {
+		records = append(records, historyRecord{Time: now, Kind: "probe", Name: r.Name, Status: r.Status, LatencyMS: r.Latency.Milliseconds()})
+	}
+	return records
+}
+
+// sentryCountRecords returns a record for every project whose count changed
+func sentryCountRecords(counts, previous map[string]int, now time.Time) []historyRecord {
+	var records []historyRecord
+	for project, count := range counts {
+		if prev, ok := previous[project]; ok && prev == count {
+			continue
+		}
+		records = append(records, historyRecord{Time: now, Kind: "sentry", Name: project, Count: count})
+	}
+	return records
+}
+
+// writeHistoryCmd appends records to the history files outside Update
+func writeHistoryCmd(s *historyStore, records []historyRecord) tea.Cmd {
+	if len(records) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := s.append(records); err != nil {
+			return statusMsg("Failed to write history: " + err.Error())
+		}
+		return nil
+	}
+}
+
+// podTransitionRecords returns a record for every pod of scope (kube
+// context/namespace) that appeared, changed status, restarted or disappeared
+// since the previous refresh of that scope. Without one (nil prevStatuses)
+// all pods are recorded as baseline.
+func podTransitionRecords(scope string, statuses map[string]string, restarts map[string]int, prevStatuses map[string]string, prevRestarts map[string]int, now time.Time) []historyRecord {
+	var records []historyRecord
+	for pod, status := range statuses {
+		prev, known := prevStatuses[pod]
+		if known && prev == status && restarts[pod] <= prevRestarts[pod] {
+			continue
+		}
+		records = append(records, historyRecord{Time: now, Kind: "pod", Name: pod, Scope: scope, Status: status, From: prev, Restarts: restarts[pod], Baseline: prevStatuses == nil})
+	}
+	for pod, prev := range prevStatuses {
+		if _, ok := statuses[pod]; !ok {
+			records = append(records, historyRecord{Time: now, Kind: "pod", Name: pod, Scope: scope, Status: "Deleted", From: prev, Restarts: prevRestarts[pod]})
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	return records
+}
+
+// issueRecords returns a record for every issue that observe flagged NEW or
+// REGRESSED during the refresh at now
+func issueRecords(issues []sentryIssue, seen *seenIssueStore, now time.Time) []historyRecord {
+	var records []historyRecord
+	for _, issue := range issues {
+		rec := seen.Issues[issue.ID]
+		status := ""
+		switch {
+		case rec.RegressedAt.Equal(now):
+			status = "REGRESSED"
+		case rec.FirstDetected.Equal(now):
+			status = "NEW"
+		default:
+			continue
+		}
+		records = append(records, historyRecord{Time: now, Kind: "issue", Name: issue.ShortID, Status: status, Title: issue.Title})
+	}
+	return records
+}
+
+// actionRecord describes something done from the TUI; action is a short
+// verb such as "resolve" and name the object it was applied to
+func actionRecord(action, name, description string) []historyRecord {
+	return []historyRecord{{Time: time.Now(), Kind: "action", Name: name, Status: action, Title: description}}
+}
+
+// restoreHistory rebuilds the in-memory state from stored records: probe
+// samples for SLOs and sparklines, and the last known Sentry counts and pod
+// states per scope so a restart does not register everything as changed.
+func restoreHistory(records []historyRecord, probes *probeHistory, sentryCounts map[string]int, podStatuses map[string]map[string]string, podRestarts map[string]map[string]int) {
+	for _, r := range records {
+		switch r.Kind {
+		case "probe":
+			probes.samples[r.Name] = append(probes.samples[r.Name], probeSample{Time: r.Time, Status: r.Status, Latency: time.Duration(r.LatencyMS) * time.Millisecond})
+		case "sentry":
+			sentryCounts[r.Name] = r.Count
+		case "pod":
+			if r.Scope == "" {
+				// recorded before pods were kept per scope
+				continue
+			}
+			if podStatuses[r.Scope] == nil {
+				podStatuses[r.Scope], podRestarts[r.Scope] = map[string]string{}, map[string]int{}
+			}
+			if r.Status == "Deleted" {
+				delete(podStatuses[r.Scope], r.Name)
+				delete(podRestarts[r.Scope], r.Name)
+				continue
+			}
+			podStatuses[r.Scope][r.Name] = r.Status
+			podRestarts[r.Scope][r.Name] = r.Restarts
+		}
+	}
+}
+
+// Probe latencies of the last hour in milliseconds, for the endpoint sparkline
+func recentLatencies(samples []probeSample, now time.Time) []int {
+	var values []int
+	cutoff := now.Add(-time.Hour)
+	for _, s := range samples {
+		if !s.Time.Before(cutoff) {
+			values = append(values, int(s.Latency.Milliseconds()))
+		}
+	}
+	return values
+}
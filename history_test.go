@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPodTransitionsPerScope(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	prod := map[string]string{"api-1": "Running", "worker-1": "Running"}
+	staging := map[string]string{"api-9": "Running"}
+
+	// a restart brings back the last known state of each scope
+	statuses, restarts := map[string]map[string]string{}, map[string]map[string]int{}
+	var stored []historyRecord
+	stored = append(stored, podTransitionRecords("prod/default", prod, map[string]int{}, nil, nil, now.Add(-time.Hour))...)
+	stored = append(stored, podTransitionRecords("staging/default", staging, map[string]int{}, nil, nil, now.Add(-time.Hour))...)
+	stored = append(stored, historyRecord{Time: now.Add(-time.Hour), Kind: "pod", Name: "unscoped", Status: "Running"})
+	restoreHistory(stored, newProbeHistory(), map[string]int{}, statuses, restarts)
+	if len(statuses) != 2 || len(statuses["prod/default"]) != 2 || len(statuses["staging/default"]) != 1 {
+		t.Fatalf("restored %v", statuses)
+	}
+
+	tests := []struct {
+		name     string
+		scope    string
+		statuses map[string]string
+		restarts map[string]int
+		want     []string // "scope name from→status"
+	}{
+		{"unchanged", "prod/default", prod, map[string]int{}, nil},
+		{"switch to another context", "staging/default", staging, map[string]int{}, nil},
+		{"switch back after a restart", "prod/default", prod, map[string]int{"worker-1": 1}, []string{"prod/default worker-1 Running→Running"}},
+		{"pod replaced", "staging/default", map[string]string{"api-10": "Pending"}, map[string]int{}, []string{"staging/default api-10 →Pending", "staging/default api-9 Running→Deleted"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := podTransitionRecords(tt.scope, tt.statuses, tt.restarts, statuses[tt.scope], restarts[tt.scope], now)
+			statuses[tt.scope], restarts[tt.scope] = tt.statuses, tt.restarts
+			if len(records) != len(tt.want) {
+				t.Fatalf("got %+v, want %v", records, tt.want)
+			}
+			for i, r := range records {
+				if got := r.Scope + " " + r.Name + " " + r.From + "→" + r.Status; got != tt.want[i] || r.Baseline {
+					t.Errorf("got %s (baseline %v), want %s", got, r.Baseline, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPodTransitionsBaseline(t *testing.T) {
+	records := podTransitionRecords("prod/default", map[string]string{"api-1": "Running"}, map[string]int{}, nil, nil, time.Now())
+	if len(records) != 1 || !records[0].Baseline || records[0].Scope != "prod/default" {
+		t.Fatalf("got %+v, want one baseline record", records)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// errJSONPathMissing is wrapped by lookup errors about a key or element the
+// document does not have; any other error is an invalid path
+var errJSONPathMissing = errors.New("not found")
+
+// lookupJSONPath evaluates a small JSONPath subset against a value decoded by
+// encoding/json: "$", ".key", "['key']" and "[index]", e.g.
+// "$.components.db.status" or "$.checks[0]['name']".
+func lookupJSONPath(doc any, path string) (any, error) {
+	p := strings.TrimSpace(path)
+	if !strings.HasPrefix(p, "$") {
+		return nil, fmt.Errorf("path %q must start with $", path)
+	}
+	p = p[1:]
+	current := doc
+	for p != "" {
+		var key string
+		var index int
+		isIndex := false
+		switch {
+		case strings.HasPrefix(p, "."):
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end == -1 {
+				end = len(p)
+			}
+			key, p = p[:end], p[end:]
+			if key == "" {
+				return nil, fmt.Errorf("empty key in %q", path)
+			}
+		case strings.HasPrefix(p, "['"):
+			end := strings.Index(p, "']")
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated bracket in %q", path)
+			}
+			key, p = p[2:end], p[end+2:]
+		case strings.HasPrefix(p, "["):
+			end := strings.Index(p, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated bracket in %q", path)
+			}
+			n, err := strconv.Atoi(p[1:end])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index %q in %q", p[1:end], path)
+			}
+			index, isIndex, p = n, true, p[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in %q", p, path)
+		}
+
+		if isIndex {
+			arr, ok := current.([]any)
+			if !ok || index >= len(arr) {
+				return nil, fmt.Errorf("%s: element %d %w", path, index, errJSONPathMissing)
+			}
+			current = arr[index]
+			continue
+		}
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: key %q %w", path, key, errJSONPathMissing)
+		}
+		value, ok := obj[key]
+		if !ok {
+			return nil, fmt.Errorf("%s: key %q %w", path, key, errJSONPathMissing)
+		}
+		current = value
+	}
+	return current, nil
+}
+
+// jsonValuesEqual compares decoded JSON values; strings compare case-insensitively
+func jsonValuesEqual(a, b any) bool {
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	if aok && bok {
+		return strings.EqualFold(as, bs)
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestLookupJSONPath(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(`{"status":"UP","checks":[{"name":"db","status":"DOWN"},{"name":"cache"}],"components":{"disk space":{"free":10}}}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path    string
+		want    any
+		missing bool // the error is errJSONPathMissing
+		invalid bool // any other error
+	}{
+		{path: "$", want: doc},
+		{path: "$.status", want: "UP"},
+		{path: "$.checks[0].status", want: "DOWN"},
+		{path: "$.checks[1]['name']", want: "cache"},
+		{path: "$['components']['disk space'].free", want: float64(10)},
+		{path: "$.nope", missing: true},
+		{path: "$.checks[2]", missing: true},
+		{path: "$.checks[1].status", missing: true},
+		{path: "$.status[0]", missing: true},
+		{path: "$.checks.name", missing: true},
+		{path: "$.checks[-1]", invalid: true},
+		{path: "$.checks[x]", invalid: true},
+		{path: "$.checks[]", invalid: true},
+		{path: "$.checks[0", invalid: true},
+		{path: "$['status", invalid: true},
+		{path: "$..status", invalid: true},
+		{path: "status", invalid: true},
+		{path: "$status", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := lookupJSONPath(doc, tt.path)
+			switch {
+			case tt.missing:
+				if !errors.Is(err, errJSONPathMissing) {
+					t.Fatalf("got %v, %v; want a missing value", got, err)
+				}
+			case tt.invalid:
+				if err == nil || errors.Is(err, errJSONPathMissing) {
+					t.Fatalf("got %v, %v; want an invalid path", got, err)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case compactJSON(got) != compactJSON(tt.want):
+				t.Errorf("got %s, want %s", compactJSON(got), compactJSON(tt.want))
+			}
+		})
+	}
+}
+
+func TestOptionalAssertionReportsInvalidPath(t *testing.T) {
+	doc := map[string]any{"checks": []any{"a"}}
+	if f := checkJSONPathAssertion(doc, jsonPathAssertion{Path: "$.missing", Optional: true}); f != "" {
+		t.Errorf("missing optional value reported: %s", f)
+	}
+	if f := checkJSONPathAssertion(doc, jsonPathAssertion{Path: "$.checks[-1]", Optional: true}); f == "" {
+		t.Error("invalid optional path not reported")
+	}
+}
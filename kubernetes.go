@@ -1,50 +1,110 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type kubectlPodsDataMsg struct {
+	kubeContext   string // as listed in; empty when not known yet
+	namespace     string
 	displayOutput string
 	podNames      []string
+	podStatuses   map[string]string
+	podRestarts   map[string]int
+	podLimits     map[string]podUsage // only the limits are set
 }
 
 // Message carrying current kubectl context name
 type kubectlContextMsg string
 
-func getKubectlPodsCmd() tea.Cmd {
+// Message carrying the namespace of the current kubectl context
+type kubectlNamespaceMsg string
+
+func getKubectlPodsCmd(kubeContext, namespace string) tea.Cmd {
 	return func() tea.Msg {
-		// Command to get clean pod names
-		cmdCleanNames := exec.Command("kubectl", "get", "pods", "--no-headers", "-o", "custom-columns=NAME:.metadata.name")
-		outputCleanNamesBytes, errCleanNames := cmdCleanNames.CombinedOutput()
-		if errCleanNames != nil {
-			return errMsg(fmt.Errorf("failed to get clean kubectl pod names: %w", errCleanNames))
+		var scope []string
+		if kubeContext != "" && namespace != "" {
+			scope = []string{"--context", kubeContext, "--namespace", namespace}
+		} else {
+			kubeContext, namespace = "", ""
+		}
+		// Pod names in display order, and their limits for the usage columns
+		cmdPods := exec.Command("kubectl", append([]string{"get", "pods", "-o", "json"}, scope...)...)
+		outputPodsBytes, errPods := cmdPods.Output()
+		if errPods != nil {
+			return errMsg(fmt.Errorf("failed to get kubectl pods: %w", errPods))
+		}
+		var pods struct {
+			Items []kubePod `json:"items"`
+		}
+		if err := json.Unmarshal(outputPodsBytes, &pods); err != nil {
+			return errMsg(fmt.Errorf("failed to parse kubectl pods: %w", err))
 		}
-		cleanPodNames := strings.Split(strings.TrimSpace(string(outputCleanNamesBytes)), "\n")
-		if len(cleanPodNames) == 1 && cleanPodNames[0] == "" {
-			cleanPodNames = []string{}
+		cleanPodNames := make([]string, 0, len(pods.Items))
+		limits := map[string]podUsage{}
+		for _, pod := range pods.Items {
+			cleanPodNames = append(cleanPodNames, pod.Metadata.Name)
+			var u podUsage
+			u.CPULimit, u.MemoryLimit = podLimits(pod)
+			limits[pod.Metadata.Name] = u
 		}
 
 		// Command to get display output (potentially colored)
-		cmdDisplay := exec.Command("kubectl", "get", "pods")
+		cmdDisplay := exec.Command("kubectl", append([]string{"get", "pods"}, scope...)...)
 		outputDisplayBytes, errDisplay := cmdDisplay.CombinedOutput()
 		if errDisplay != nil {
 			return errMsg(fmt.Errorf("failed to get kubectl pods for display: %w", errDisplay))
 		}
 		displayOutput := string(outputDisplayBytes)
+		podStatuses := parsePodStatuses(displayOutput)
 
-		// Colorize for display after getting clean names
-		coloredDisplayOutput := colorizeKubectlPodsWithSelection(displayOutput, -1) // -1 for no initial selection highlighting
-
+		// Colorized when rendered, after the usage columns are added
 		return kubectlPodsDataMsg{
-			displayOutput: coloredDisplayOutput,
+			kubeContext:   kubeContext,
+			namespace:     namespace,
+			displayOutput: displayOutput,
 			podNames:      cleanPodNames,
+			podStatuses:   podStatuses,
+			podRestarts:   parsePodRestarts(displayOutput),
+			podLimits:     limits,
+		}
+	}
+}
+
+// Map pod name to the STATUS column of `kubectl get pods`
+func parsePodStatuses(output string) map[string]string {
+	statuses := map[string]string{}
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if i == 0 || len(fields) < 3 {
+			continue
 		}
+		statuses[fields[0]] = fields[2]
 	}
+	return statuses
+}
+
+// Map pod name to the RESTARTS column, which may read "3 (12m ago)"
+func parsePodRestarts(output string) map[string]int {
+	restarts := map[string]int{}
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if i == 0 || len(fields) < 4 {
+			continue
+		}
+		if n, err := strconv.Atoi(fields[3]); err == nil {
+			restarts[fields[0]] = n
+		}
+	}
+	return restarts
 }
 
 // Fetch currently used kubectl context by parsing `kubectl config get-contexts`
@@ -73,6 +133,21 @@ func getKubectlContextCmd() tea.Cmd {
 	}
 }
 
+func getKubectlNamespaceCmd() tea.Cmd {
+	return func() tea.Msg {
+		cmd := exec.Command("kubectl", "config", "view", "--minify", "-o", "jsonpath={..namespace}")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return errMsg(fmt.Errorf("failed to get kubectl namespace: %w", err))
+		}
+		ns := strings.TrimSpace(string(out))
+		if ns == "" {
+			ns = "default"
+		}
+		return kubectlNamespaceMsg(ns)
+	}
+}
+
 func colorizeKubectlPods(output string) string {
 	return colorizeKubectlPodsWithSelection(output, -1)
 }
@@ -104,6 +179,10 @@ func colorizeKubectlPodsWithSelection(output string, selectedIndex int) string {
 				currentLineStyle = currentLineStyle.Copy().Foreground(errorStyle.GetForeground())
 			}
 		}
+		if strings.HasSuffix(line, " !") {
+			// high CPU or memory usage, see withUsageColumns
+			currentLineStyle = currentLineStyle.Copy().Foreground(errorStyle.GetForeground())
+		}
 		coloredLines = append(coloredLines, currentLineStyle.Render(line))
 	}
 	return strings.Join(coloredLines, "\n")
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/template"
+
+	osc52 "github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func renderURLTemplate(tmpl string, data any) (string, error) {
+	t, err := template.New("url").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// selectedLink resolves the URL of whatever is selected in the focused pane:
+// the Sentry issue, the health endpoint or the pod.
+func (m model) selectedLink() (label, url string, err error) {
+	env := m.cfg.environment(m.currentKubeContext)
+	switch m.selectedPane {
+	case 0:
+		if m.selectedIssue >= len(m.sentryIssues) {
+			return "", "", errors.New("no Sentry issue selected")
+		}
+		issue := m.sentryIssues[m.selectedIssue]
+		if env.IssueURL == "" {
+			if issue.Permalink == "" {
+				return "", "", fmt.Errorf("%s has no permalink", issue.ShortID)
+			}
+			return issue.ShortID, issue.Permalink, nil
+		}
+		url, err = renderURLTemplate(env.IssueURL, map[string]string{
+			"ID":        issue.ID,
+			"ShortID":   issue.ShortID,
+			"Project":   issue.Project,
+			"Org":       m.cfg.Sentry.Org,
+			"Permalink": issue.Permalink,
+		})
+		return issue.ShortID, url, err
+	case 1:
+		rows := healthRows(m.apiResponseTimes, m.healthToggled)
+		if m.selectedHealthRow >= len(rows) {
+			return "", "", errors.New("no endpoint selected")
+		}
+		row := rows[m.selectedHealthRow]
+		name, target := m.apiResponseTimes[row.endpoint].Name, m.apiResponseTimes[row.endpoint].URL
+		if row.node != nil && row.node.URL != "" {
+			// groups and fetched components have their own URL
+			name, target = row.node.Name, row.node.URL
+		}
+		if env.EndpointURL == "" {
+			return name, target, nil
+		}
+		url, err = renderURLTemplate(env.EndpointURL, map[string]string{
+			"Name": name,
+			"URL":  target,
+		})
+		return name, url, err
+	case 2:
+		if m.selectedPodIndex >= len(m.podNames) {
+			return "", "", errors.New("no pod selected")
+		}
+		pod := m.podNames[m.selectedPodIndex]
+		if env.PodURL == "" {
+			return "", "", fmt.Errorf("no podUrl configured for context %q", m.currentKubeContext)
+		}
+		url, err = renderURLTemplate(env.PodURL, map[string]string{
+			"Pod":       pod,
+			"Namespace": m.currentNamespace,
+			"Context":   m.currentKubeContext,
+		})
+		return pod, url, err
+	}
+	return "", "", errors.New("nothing selected")
+}
+
+func openURLCmd(label, url string) tea.Cmd {
+	return func() tea.Msg {
+		opener := "xdg-open"
+		if runtime.GOOS == "darwin" {
+			opener = "open"
+		}
+		cmd := exec.Command(opener, url)
+		if err := cmd.Start(); err != nil {
+			return statusMsg(fmt.Sprintf("Failed to open %s: %v", label, err))
+		}
+		go func() { _ = cmd.Wait() }()
+		return statusMsg("Opened " + label + " in browser")
+	}
+}
+
+// Copy via OSC52 so the terminal, not the host, owns the clipboard; this also
+// works over SSH and inside tmux/screen.
+func copyURLCmd(label, url string) tea.Cmd {
+	return func() tea.Msg {
+		if err := copyToClipboard(url); err != nil {
+			return statusMsg(fmt.Sprintf("Failed to copy %s: %v", label, err))
+		}
+		return statusMsg("Copied link for " + label + ": " + url)
+	}
+}
+
+// Copy arbitrary text such as the handoff summary
+func copyTextCmd(label, text string) tea.Cmd {
+	return func() tea.Msg {
+		if err := copyToClipboard(text); err != nil {
+			return statusMsg(fmt.Sprintf("Failed to copy %s: %v", label, err))
+		}
+		return statusMsg("Copied " + label + " to the clipboard")
+	}
+}
+
+func copyToClipboard(text string) error {
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		seq = seq.Screen()
+	}
+	_, err := seq.WriteTo(os.Stderr)
+	return err
+}
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	lipgloss "github.com/charmbracelet/lipgloss"
 )
@@ -12,22 +19,116 @@ import (
 const appVersion = "0.0.1"
 
 type model struct {
-	width            int
-	height           int
-	sentryLogs       string
-	sentryStats      string
-	kubectlPods      string
-	apiResponseTimes string
-	selectedPane     int // 0: Sentry Errors, 1: Analytics, 2: Pod Status
-	selectedPodIndex int
-	podNames         []string // To store actual pod names for logs
+	cfg               config
+	width             int
+	height            int
+	sentryProjects    []string
+	sentryIssues      []sentryIssue
+	sentryStats       map[string]int
+	sentryVolume      map[string][]int
+	sentryQuery       string
+	sentrySort        string
+	queryInput        textinput.Model
+	editingQuery      bool
+	kubectlPods       string
+	apiResponseTimes  []endpointResult
+	selectedPane      int // 0: Sentry Errors, 1: Analytics, 2: Pod Status, 3: Warning Events
+	selectedPodIndex  int
+	podNames          []string // To store actual pod names for logs
+	selectedIssue     int
+	selectedHealthRow int
+	healthToggled     map[string]bool // see healthRows
+	probeHistory      *probeHistory
+	history           *historyStore
+	timeline          *timeline
+	podStatuses       map[string]string // last refresh, for the report and workloads
+	podRestarts       map[string]int
+	sloStatuses       map[string][]sloStatus // by check name
+
+	// last refresh of each kube context/namespace, to detect transitions
+	scopedPodStatuses map[string]map[string]string
+	scopedPodRestarts map[string]map[string]int
 
 	logViewer     podLogViewerModel
 	showLogViewer bool
 
+	showTimeline     bool
+	timelineSource   int // index into timelineSources
+	timelineRange    int // index into timelineRanges
+	timelineViewport viewport.Model
+
+	showHandoff     bool
+	handoffMarkdown bool
+	handoffText     string
+	handoffViewport viewport.Model
+	handoffData     *handoffData // cached, see refreshHandoff
+
+	notes          []incidentNote
+	showNotes      bool
+	noteInput      textarea.Model
+	noteSearch     textinput.Model
+	searchingNotes bool
+	noteLinked     bool   // link new notes to noteIssue/notePod
+	noteIssue      string // selection when the notes were opened
+	notePod        string
+
+	podAction     *podActionTarget // awaiting confirmation
+	replicasInput textinput.Model
+
+	execTarget         *podActionTarget // choosing a container to exec into
+	execContainers     []string
+	execContainerIndex int
+
+	warningEvents    []warningGroup
+	warningEventsErr string
+	selectedEvent    int
+
+	// Streamed by restartWarningWatch; scope → event UID → event
+	warningWatchCh    chan warningWatchMsg
+	warningEventSet   map[string]map[string]kubeEvent
+	warningWatchKey   string // context and scopes being watched
+	warningGeneration int
+	warningCancel     context.CancelFunc
+
+	workloads           []workload
+	workloadsErr        string
+	showWorkloads       bool
+	selectedWorkloadRow int
+	workloadExpanded    map[string]bool   // by workloadKey
+	deployRevisions     map[string]string // revisionKey → revision seen last
+	deploys             []deploy
+
+	podUsageSamples   map[string][]podUsage // most recent last
+	podMetricsErr     string
+	podResourceLimits map[string]podUsage // from the pods pane's pod list
+
+	showPodDetail      bool
+	podDetailName      string
+	podDetailContext   string
+	podDetailNamespace string
+	podDetailContent   string
+	podDetailViewport  viewport.Model
+
+	portForwards       []*portForward
+	forwardEvents      chan portForwardMsg
+	forwardsDone       chan struct{} // closed on exit, see startPortForward
+	nextForwardID      int
+	forwardOptions     []forwardOption // choosing the port of a new forward
+	forwardOptionIndex int
+	forwardPod         string
+	localPortInput     textinput.Model
+	showForwards       bool
+	selectedForward    int
+
+	showHelp bool
+
 	currentKubeContext     string
-	podHighUsage           map[string]bool
+	currentNamespace       string
+	podHighUsage           map[string]bool // at or above the usage thresholds
 	lastSentryErrorsUpdate time.Time
+	firingAlerts           map[string]bool
+	seenIssues             *seenIssueStore
+	statusMessage          string
 
 	// Splash
 	showSplash      bool
@@ -41,13 +142,19 @@ type errMsg error
 
 func (m model) Init() tea.Cmd {
 	return tea.Batch(
-		getSentryErrorLogsCmd(),
-		getSentryStatsCmd(),
-		getKubectlPodsCmd(),
+		getSentryErrorLogsCmd(m.cfg.Sentry, m.sentryQuery, m.sentrySort),
+		getSentryStatsCmd(m.cfg.Sentry),
+		getSentryProjectVolumeCmd(m.cfg.Sentry),
+		getKubectlPodsCmd(m.currentKubeContext, m.currentNamespace),
 		getKubectlContextCmd(),
-		getApiResponseTimesCmd(),
+		getKubectlNamespaceCmd(),
+		getWorkloadsCmd(m.currentKubeContext, m.currentNamespace),
+		getPodMetricsCmd(m.currentKubeContext, m.currentNamespace),
+		getApiResponseTimesCmd(m.cfg.HealthChecks, m.cfg.HealthTimeoutMS),
 		splashTimerCmd(),
 		tickCmd(),
+		waitForwardCmd(m.forwardEvents),
+		waitWarningWatchCmd(m.warningWatchCh),
 	)
 }
 
@@ -56,6 +163,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds []tea.Cmd
 	)
 
+	// Forwards report whatever overlay is open, and each message re-arms the
+	// wait for the next one
+	if msg, ok := msg.(portForwardMsg); ok {
+		return m.handlePortForwardMsg(msg)
+	}
+	if msg, ok := msg.(warningWatchMsg); ok {
+		return m.handleWarningWatchMsg(msg)
+	}
+
 	if m.showLogViewer {
 		oldLogViewer, logCmd := m.logViewer.Update(msg)
 		m.logViewer = oldLogViewer.(podLogViewerModel)
@@ -71,25 +187,360 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 	}
 
+	if m.showTimeline {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			switch key.String() {
+			case "esc", "q", "T":
+				m.showTimeline = false
+			case "ctrl+c":
+				return m, tea.Quit
+			case "f":
+				m.timelineSource = (m.timelineSource + 1) % len(timelineSources)
+				m.refreshTimeline()
+			case "r":
+				m.timelineRange = (m.timelineRange + 1) % len(timelineRanges)
+				m.refreshTimeline()
+			default:
+				var cmd tea.Cmd
+				m.timelineViewport, cmd = m.timelineViewport.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+		if _, ok := msg.(tea.MouseMsg); ok {
+			var cmd tea.Cmd
+			m.timelineViewport, cmd = m.timelineViewport.Update(msg)
+			return m, cmd
+		}
+		// everything else keeps updating the dashboard underneath
+	}
+
+	if m.showWorkloads {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			rows := workloadRows(m.workloads, m.workloadExpanded)
+			switch key.String() {
+			case "esc", "q", "W":
+				m.showWorkloads = false
+			case "ctrl+c":
+				return m, tea.Quit
+			case "up", "k":
+				if len(rows) > 0 {
+					m.selectedWorkloadRow = (m.selectedWorkloadRow + len(rows) - 1) % len(rows)
+				}
+			case "down", "j":
+				if len(rows) > 0 {
+					m.selectedWorkloadRow = (m.selectedWorkloadRow + 1) % len(rows)
+				}
+			case "enter", " ":
+				if m.selectedWorkloadRow < len(rows) {
+					row := rows[m.selectedWorkloadRow]
+					key := workloadKey(m.workloads[row.workload])
+					m.workloadExpanded[key] = !m.workloadExpanded[key]
+					if row.pod != "" {
+						// collapsing from a pod row selects its workload
+						for i, r := range rows {
+							if r.workload == row.workload {
+								m.selectedWorkloadRow = i
+								break
+							}
+						}
+					}
+				}
+			case "l":
+				if m.selectedWorkloadRow < len(rows) && rows[m.selectedWorkloadRow].pod != "" {
+					pod := rows[m.selectedWorkloadRow].pod
+					m.logViewer = newPodLogViewerModel(pod)
+					m.showLogViewer = true
+					return m, tea.Batch(sendWindowSizeCmd(m.width, m.height), getPodLogsCmd(pod))
+				}
+			}
+			return m, nil
+		}
+	}
+
+	if m.showPodDetail {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			switch key.String() {
+			case "esc", "q", "d":
+				m.showPodDetail = false
+			case "ctrl+c":
+				return m, tea.Quit
+			case "r":
+				return m, getPodDetailCmd(m.podDetailName, m.podDetailContext, m.podDetailNamespace)
+			default:
+				var cmd tea.Cmd
+				m.podDetailViewport, cmd = m.podDetailViewport.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+		if _, ok := msg.(tea.MouseMsg); ok {
+			var cmd tea.Cmd
+			m.podDetailViewport, cmd = m.podDetailViewport.Update(msg)
+			return m, cmd
+		}
+	}
+
+	if m.showHandoff {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			switch key.String() {
+			case "esc", "q", "H":
+				m.showHandoff = false
+			case "ctrl+c":
+				return m, tea.Quit
+			case "f":
+				m.handoffMarkdown = !m.handoffMarkdown
+				return m, m.refreshHandoff()
+			case "y":
+				return m, copyTextCmd("handoff summary", m.handoffText)
+			default:
+				var cmd tea.Cmd
+				m.handoffViewport, cmd = m.handoffViewport.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+		if _, ok := msg.(tea.MouseMsg); ok {
+			var cmd tea.Cmd
+			m.handoffViewport, cmd = m.handoffViewport.Update(msg)
+			return m, cmd
+		}
+	}
+
+	if m.showNotes {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			if m.searchingNotes {
+				switch key.String() {
+				case "enter", "esc":
+					m.searchingNotes = false
+					m.noteSearch.Blur()
+					return m, m.noteInput.Focus()
+				}
+				var cmd tea.Cmd
+				m.noteSearch, cmd = m.noteSearch.Update(msg)
+				return m, cmd
+			}
+			switch key.String() {
+			case "esc":
+				m.showNotes = false
+				m.noteInput.Blur()
+				return m, nil
+			case "ctrl+c":
+				return m, tea.Quit
+			case "ctrl+s":
+				m.saveNote()
+				return m, nil
+			case "ctrl+l":
+				m.noteLinked = !m.noteLinked
+				return m, nil
+			case "ctrl+f":
+				m.searchingNotes = true
+				m.noteInput.Blur()
+				return m, m.noteSearch.Focus()
+			}
+		}
+		var cmd tea.Cmd
+		m.noteInput, cmd = m.noteInput.Update(msg)
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return m, cmd
+		}
+		cmds = append(cmds, cmd)
+		// everything else keeps updating the dashboard underneath
+	}
+
+	if m.podAction != nil {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			switch key.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "esc":
+				m.podAction = nil
+				m.replicasInput.Blur()
+				m.statusMessage = "Cancelled"
+				return m, nil
+			}
+			if m.podAction.Action == "scale" {
+				if key.String() == "enter" {
+					return m.confirmPodAction()
+				}
+				var cmd tea.Cmd
+				m.replicasInput, cmd = m.replicasInput.Update(msg)
+				return m, cmd
+			}
+			switch key.String() {
+			case "y":
+				return m.confirmPodAction()
+			case "n":
+				m.podAction = nil
+				m.statusMessage = "Cancelled"
+			}
+			return m, nil
+		}
+	}
+
+	if m.execTarget != nil {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			switch key.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "esc", "q":
+				m.execTarget = nil
+				m.statusMessage = ""
+			case "up", "k":
+				m.execContainerIndex = (m.execContainerIndex + len(m.execContainers) - 1) % len(m.execContainers)
+			case "down", "j":
+				m.execContainerIndex = (m.execContainerIndex + 1) % len(m.execContainers)
+			case "enter":
+				return m.chooseExecContainer(*m.execTarget, m.execContainers[m.execContainerIndex])
+			}
+			return m, nil
+		}
+	}
+
+	if m.forwardOptions != nil {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			switch key.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "esc":
+				m.forwardOptions = nil
+				m.localPortInput.Blur()
+				m.statusMessage = ""
+				return m, nil
+			case "up":
+				m.forwardOptionIndex = (m.forwardOptionIndex + len(m.forwardOptions) - 1) % len(m.forwardOptions)
+				return m, nil
+			case "down":
+				m.forwardOptionIndex = (m.forwardOptionIndex + 1) % len(m.forwardOptions)
+				return m, nil
+			case "enter":
+				return m.confirmForward()
+			}
+			var cmd tea.Cmd
+			m.localPortInput, cmd = m.localPortInput.Update(msg)
+			return m, cmd
+		}
+	}
+
+	if m.showForwards {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			switch key.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "esc", "q", "F":
+				m.showForwards = false
+			case "up", "k":
+				if len(m.portForwards) > 0 {
+					m.selectedForward = (m.selectedForward + len(m.portForwards) - 1) % len(m.portForwards)
+				}
+			case "down", "j":
+				if len(m.portForwards) > 0 {
+					m.selectedForward = (m.selectedForward + 1) % len(m.portForwards)
+				}
+			case "x":
+				if m.selectedForward < len(m.portForwards) {
+					m.portForwards[m.selectedForward].stop()
+				}
+			case "c":
+				m.clearForwards()
+			}
+			return m, nil
+		}
+	}
+
+	if m.showHelp {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			switch key.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "esc", "q", "?":
+				m.showHelp = false
+			}
+			return m, nil
+		}
+	}
+
+	if m.editingQuery {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			switch key.String() {
+			case "enter":
+				m.editingQuery = false
+				m.queryInput.Blur()
+				return m.applySentryQuery(strings.TrimSpace(m.queryInput.Value()))
+			case "esc":
+				m.editingQuery = false
+				m.queryInput.Blur()
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.queryInput, cmd = m.queryInput.Update(msg)
+			return m, cmd
+		}
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
 		case "up", "k":
+			if m.selectedPane == 0 && len(m.sentryIssues) > 0 {
+				m.selectedIssue--
+				if m.selectedIssue < 0 {
+					m.selectedIssue = len(m.sentryIssues) - 1
+				}
+			}
+			if rows := healthRows(m.apiResponseTimes, m.healthToggled); m.selectedPane == 1 && len(rows) > 0 {
+				m.selectedHealthRow--
+				if m.selectedHealthRow < 0 {
+					m.selectedHealthRow = len(rows) - 1
+				}
+			}
 			if m.selectedPane == 2 && len(m.podNames) > 0 {
 				m.selectedPodIndex--
 				if m.selectedPodIndex < 0 {
 					m.selectedPodIndex = len(m.podNames) - 1
 				}
 			}
+			if m.selectedPane == 3 && len(m.warningEvents) > 0 {
+				m.selectedEvent--
+				if m.selectedEvent < 0 {
+					m.selectedEvent = len(m.warningEvents) - 1
+				}
+			}
 		case "down", "j":
+			if m.selectedPane == 0 && len(m.sentryIssues) > 0 {
+				m.selectedIssue++
+				if m.selectedIssue >= len(m.sentryIssues) {
+					m.selectedIssue = 0
+				}
+			}
+			if rows := healthRows(m.apiResponseTimes, m.healthToggled); m.selectedPane == 1 && len(rows) > 0 {
+				m.selectedHealthRow++
+				if m.selectedHealthRow >= len(rows) {
+					m.selectedHealthRow = 0
+				}
+			}
 			if m.selectedPane == 2 && len(m.podNames) > 0 {
 				m.selectedPodIndex++
 				if m.selectedPodIndex >= len(m.podNames) {
 					m.selectedPodIndex = 0
 				}
 			}
+			if m.selectedPane == 3 && len(m.warningEvents) > 0 {
+				m.selectedEvent++
+				if m.selectedEvent >= len(m.warningEvents) {
+					m.selectedEvent = 0
+				}
+			}
+		case "enter", " ":
+			rows := healthRows(m.apiResponseTimes, m.healthToggled)
+			if m.selectedPane == 1 && m.selectedHealthRow < len(rows) {
+				m.healthToggled[rows[m.selectedHealthRow].key] = !m.healthToggled[rows[m.selectedHealthRow].key]
+			}
+			if m.selectedPane == 3 {
+				return m.jumpToEventPod()
+			}
 		case "l":
 			if m.selectedPane == 2 && len(m.podNames) > 0 && m.selectedPodIndex < len(m.podNames) {
 				selectedPod := m.podNames[m.selectedPodIndex]
@@ -100,38 +551,331 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					getPodLogsCmd(selectedPod),
 				)
 			}
+		case "R":
+			if m.selectedPane == 0 && m.selectedIssue < len(m.sentryIssues) {
+				issue := m.sentryIssues[m.selectedIssue]
+				m.statusMessage = "Resolving " + issue.ShortID + "..."
+				return m, resolveSentryIssueCmd(m.cfg.Sentry, issue)
+			}
+			if m.selectedPane == 2 {
+				return m.startPodAction(podActionKeys["R"])
+			}
+		case "e":
+			if m.selectedPane == 2 {
+				return m.startExec()
+			}
+		case "d":
+			if m.selectedPane == 2 {
+				return m.openPodDetail()
+			}
+		case "p":
+			if m.selectedPane == 2 {
+				return m.startForwardPicker()
+			}
+		case "F":
+			m.showForwards = true
+			return m, nil
+		case "W":
+			m.showWorkloads = true
+			return m, nil
+		case "?":
+			m.showHelp = true
+			return m, nil
+		case "D", "S", "C":
+			if m.selectedPane == 2 {
+				return m.startPodAction(podActionKeys[msg.String()])
+			}
+		case "E":
+			m.statusMessage = "Exporting incident report..."
+			return m, exportReportCmd(m.cfg, m.snapshotReport(time.Now()))
+		case "H":
+			m.showHandoff = true
+			m.handoffMarkdown = true
+			return m, m.refreshHandoff()
+		case "n":
+			m.showNotes = true
+			m.noteIssue, m.notePod = m.noteLink()
+			m.noteLinked = true
+			m.noteInput.SetWidth(m.width)
+			return m, m.noteInput.Focus()
+		case "T":
+			m.showTimeline = true
+			m.refreshTimeline()
+			m.timelineViewport.GotoBottom()
+			return m, nil
+		case "o", "y":
+			label, url, err := m.selectedLink()
+			if err != nil {
+				m.statusMessage = err.Error()
+				return m, nil
+			}
+			if msg.String() == "o" {
+				return m, openURLCmd(label, url)
+			}
+			return m, copyURLCmd(label, url)
+		case "/":
+			if m.selectedPane == 0 {
+				m.editingQuery = true
+				m.queryInput.SetValue(m.sentryQuery)
+				m.queryInput.CursorEnd()
+				return m, m.queryInput.Focus()
+			}
+		case "s":
+			if m.selectedPane == 0 {
+				for i, key := range sentrySortKeys {
+					if key == m.sentrySort {
+						m.sentrySort = sentrySortKeys[(i+1)%len(sentrySortKeys)]
+						break
+					}
+				}
+				sortSentryIssues(m.sentryProjects, m.sentryIssues, m.sentrySort)
+				m.statusMessage = "Sorting Sentry issues by " + m.sentrySort
+				return m, getSentryErrorLogsCmd(m.cfg.Sentry, m.sentryQuery, m.sentrySort)
+			}
+		case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			if m.selectedPane == 0 {
+				if msg.String() == "0" {
+					return m.applySentryQuery(m.cfg.Sentry.DefaultQuery)
+				}
+				idx := int(msg.String()[0] - '1')
+				if idx < len(m.cfg.Sentry.SavedQueries) {
+					m.statusMessage = "Saved query: " + m.cfg.Sentry.SavedQueries[idx].Name
+					return m.applySentryQuery(m.cfg.Sentry.SavedQueries[idx].Query)
+				}
+			}
 		case "tab":
-			m.selectedPane = (m.selectedPane + 1) % 3
+			m.selectedPane = (m.selectedPane + 1) % 4
 			m.selectedPodIndex = 0
 		case "shift+tab":
 			m.selectedPane--
 			if m.selectedPane < 0 {
-				m.selectedPane = 2
+				m.selectedPane = 3
 			}
 			m.selectedPodIndex = 0
 		}
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		if m.showTimeline {
+			m.refreshTimeline()
+		}
+		if m.showHandoff {
+			cmds = append(cmds, m.refreshHandoff())
+		}
+		if m.showPodDetail {
+			m.setPodDetail(m.podDetailContent)
+		}
+		m.noteInput.SetWidth(msg.Width)
 	case sentryErrorLogsMsg:
-		m.sentryLogs = string(msg)
+		if msg.query != m.sentryQuery {
+			// response to a query that has since been replaced
+			return m, nil
+		}
+		m.sentryProjects = msg.projects
+		m.sentryIssues = msg.issues
+		// Only the default query tracks arrivals; ad-hoc queries would make
+		// every issue outside the previous result set look new
+		if msg.query == m.cfg.Sentry.DefaultQuery {
+			now := time.Now()
+			if err := m.seenIssues.observe(m.sentryIssues, now); err != nil {
+				m.statusMessage = "Failed to persist seen Sentry issues: " + err.Error()
+			}
+			cmds = append(cmds, m.recordHistory(issueRecords(m.sentryIssues, m.seenIssues, now)))
+		} else {
+			m.seenIssues.applyBadges(m.sentryIssues, time.Now())
+		}
+		markDeployIssues(m.sentryIssues, m.deploys, m.cfg.Sentry.Projects, m.deployWindow())
+		sortSentryIssues(m.sentryProjects, m.sentryIssues, m.sentrySort)
+		if m.selectedIssue >= len(m.sentryIssues) {
+			m.selectedIssue = 0
+		}
 		m.lastSentryErrorsUpdate = time.Now()
 		m.initDataArrived = true
+	case sentryIssueResolvedMsg:
+		issue := sentryIssue(msg)
+		for i := range m.sentryIssues {
+			if m.sentryIssues[i].ID == issue.ID {
+				m.sentryIssues = append(m.sentryIssues[:i], m.sentryIssues[i+1:]...)
+				break
+			}
+		}
+		if m.selectedIssue >= len(m.sentryIssues) {
+			m.selectedIssue = 0
+		}
+		m.statusMessage = "Resolved " + issue.ShortID
+		return m, tea.Batch(m.recordHistory(actionRecord("resolve", issue.ShortID, fmt.Sprintf("Resolved %s: %s", issue.ShortID, issue.Title))), sendNotificationCmd(m.cfg, notification{
+			Event:       "resolved",
+			Title:       issue.Title,
+			ShortID:     issue.ShortID,
+			Status:      "resolved",
+			KubeContext: m.currentKubeContext,
+			Time:        time.Now(),
+		}))
+	case sentryProjectVolumeMsg:
+		m.sentryVolume = msg
+	case statusMsg:
+		m.statusMessage = string(msg)
 	case sentryStatsMsg:
-		m.sentryStats = string(msg)
+		cmds = append(cmds, m.recordHistory(sentryCountRecords(msg, m.sentryStats, time.Now())))
+		m.sentryStats = msg
 		m.initDataArrived = true
 	case kubectlPodsDataMsg:
 		m.kubectlPods = msg.displayOutput
 		m.podNames = msg.podNames
+		m.podResourceLimits = msg.podLimits
 		if m.selectedPodIndex >= len(m.podNames) {
 			m.selectedPodIndex = 0
 		}
 		m.initDataArrived = true
+		for _, n := range evaluateAlertRules(m.cfg.AlertRules, "pod", msg.podStatuses, m.firingAlerts, m.currentKubeContext) {
+			cmds = append(cmds, sendNotificationCmd(m.cfg, n))
+		}
+		if msg.kubeContext != "" {
+			// pods are compared per scope, so switching context or namespace
+			// does not register every pod as deleted or new
+			scope := msg.kubeContext + "/" + msg.namespace
+			cmds = append(cmds, m.recordHistory(podTransitionRecords(scope, msg.podStatuses, msg.podRestarts, m.scopedPodStatuses[scope], m.scopedPodRestarts[scope], time.Now())))
+			m.scopedPodStatuses[scope] = msg.podStatuses
+			m.scopedPodRestarts[scope] = msg.podRestarts
+		}
+		m.podStatuses = msg.podStatuses
+		m.podRestarts = msg.podRestarts
+	case podActionTargetMsg:
+		if msg.err != nil {
+			m.statusMessage = msg.err.Error()
+			return m, nil
+		}
+		m.statusMessage = ""
+		m.podAction = &msg.target
+		if msg.target.Action == "scale" {
+			m.replicasInput.SetValue(strconv.Itoa(msg.target.Replicas))
+			m.replicasInput.CursorEnd()
+			return m, m.replicasInput.Focus()
+		}
+	case podActionDoneMsg:
+		if err := m.writeAudit(msg); err != nil {
+			m.statusMessage = "Failed to write audit log: " + err.Error()
+		}
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("%s failed: %s", msg.target.describe(), firstLine(msg.output, msg.err))
+			return m, nil
+		}
+		m.statusMessage = msg.target.describe() + ": done"
+		return m, tea.Batch(m.recordHistory(actionRecord(msg.target.Action, msg.target.Pod, msg.target.describe())), getKubectlPodsCmd(m.currentKubeContext, m.currentNamespace))
+	case podDetailMsg:
+		if m.showPodDetail && msg.pod == m.podDetailName {
+			if msg.err != nil {
+				m.setPodDetail(statusUnresolvedStyle.Render(msg.err.Error()))
+			} else {
+				m.setPodDetail(msg.detail)
+			}
+		}
+	case podMetricsMsg:
+		m.setPodMetrics(msg)
+	case workloadsMsg:
+		m.workloadsErr = ""
+		if msg.err != nil {
+			m.workloadsErr = msg.err.Error()
+		} else {
+			m.workloads = msg.workloads
+			var records []historyRecord
+			if msg.kubeContext != "" {
+				// revisions are kept per scope, so deploys made while another
+				// context was selected are found when switching back
+				scope := msg.kubeContext + "/" + msg.namespace
+				records = detectDeploys(m.deployRevisions, m.deploys, scope, m.workloads, time.Now())
+			}
+			for _, r := range records {
+				m.deploys = append(m.deploys, deployOf(r))
+			}
+			cmds = append(cmds, m.recordHistory(records))
+			if len(records) > 0 {
+				markDeployIssues(m.sentryIssues, m.deploys, m.cfg.Sentry.Projects, m.deployWindow())
+			}
+		}
+		if m.selectedWorkloadRow >= len(workloadRows(m.workloads, m.workloadExpanded)) {
+			m.selectedWorkloadRow = 0
+		}
+	case forwardOptionsMsg:
+		if msg.err != nil {
+			m.statusMessage = msg.err.Error()
+			return m, nil
+		}
+		m.statusMessage = ""
+		m.forwardPod = msg.pod
+		m.forwardOptions = msg.options
+		m.forwardOptionIndex = 0
+		m.localPortInput.Reset()
+		return m, m.localPortInput.Focus()
+	case execContainersMsg:
+		if msg.err != nil {
+			m.statusMessage = msg.err.Error()
+			return m, nil
+		}
+		if len(msg.containers) == 1 {
+			return m.chooseExecContainer(msg.target, msg.containers[0])
+		}
+		m.statusMessage = ""
+		m.execTarget = &msg.target
+		m.execContainers = msg.containers
+		m.execContainerIndex = 0
+	case execShellMsg:
+		if msg.err != nil {
+			m.statusMessage = msg.err.Error()
+			return m, nil
+		}
+		m.statusMessage = ""
+		return m, execShell(msg.target, msg.shell)
+	case execDoneMsg:
+		if err := m.writeAudit(podActionDoneMsg{target: msg.target, args: msg.args, err: msg.err}); err != nil {
+			m.statusMessage = "Failed to write audit log: " + err.Error()
+		} else if msg.err != nil {
+			m.statusMessage = "Shell in " + msg.target.Pod + " " + exitStatus(msg.err)
+		} else {
+			m.statusMessage = "Shell in " + msg.target.Pod + " closed"
+		}
+		// a shell exits with the status of its last command, so the session
+		// is recorded either way
+		cmds = append(cmds, m.recordHistory(actionRecord(msg.target.Action, msg.target.Pod, msg.target.describe())))
+	case handoffLoadedMsg:
+		m.handoffData = msg
+		if m.showHandoff {
+			cmds = append(cmds, m.refreshHandoff())
+		}
 	case kubectlContextMsg:
 		m.currentKubeContext = string(msg)
+		m.restartWarningWatch()
+	case kubectlNamespaceMsg:
+		m.currentNamespace = string(msg)
+		m.restartWarningWatch()
 	case apiResponseTimesMsg:
-		m.apiResponseTimes = string(msg)
+		m.apiResponseTimes = msg
+		if m.selectedHealthRow >= len(healthRows(m.apiResponseTimes, m.healthToggled)) {
+			m.selectedHealthRow = 0
+		}
 		m.initDataArrived = true
+		statuses := map[string]string{}
+		for _, r := range msg {
+			statuses[r.Name] = r.Status
+		}
+		for _, n := range evaluateAlertRules(m.cfg.AlertRules, "endpoint", statuses, m.firingAlerts, m.currentKubeContext) {
+			cmds = append(cmds, sendNotificationCmd(m.cfg, n))
+		}
+		now := time.Now()
+		m.probeHistory.record(msg, now, sloRetention(m.cfg.HealthChecks))
+		cmds = append(cmds, m.recordHistory(probeRecords(msg, now)))
+		m.sloStatuses = map[string][]sloStatus{}
+		burnStates := map[string]string{}
+		for _, check := range m.cfg.HealthChecks {
+			if check.SLO == nil {
+				continue
+			}
+			m.sloStatuses[check.Name] = evaluateSLOs(check, m.probeHistory.samples[check.Name], now)
+			burnStates[check.Name] = worstSLOState(m.sloStatuses[check.Name])
+		}
+		for _, n := range evaluateAlertRules(m.cfg.AlertRules, "slo", burnStates, m.firingAlerts, m.currentKubeContext) {
+			cmds = append(cmds, sendNotificationCmd(m.cfg, n))
+		}
 	case splashTimerMsg:
 		m.splashTimerDone = true
 	case tickMsg:
@@ -140,13 +884,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.showSplash = false
 		}
 		batch := []tea.Cmd{
-			getSentryStatsCmd(),
-			getApiResponseTimesCmd(),
-			getKubectlPodsCmd(),
+			getSentryStatsCmd(m.cfg.Sentry),
+			getApiResponseTimesCmd(m.cfg.HealthChecks, m.cfg.HealthTimeoutMS),
+			getKubectlPodsCmd(m.currentKubeContext, m.currentNamespace),
 			getKubectlContextCmd(),
+			getKubectlNamespaceCmd(),
+			getWorkloadsCmd(m.currentKubeContext, m.currentNamespace),
+			getPodMetricsCmd(m.currentKubeContext, m.currentNamespace),
 		}
 		if time.Since(m.lastSentryErrorsUpdate) >= 60*time.Second || m.lastSentryErrorsUpdate.IsZero() {
-			batch = append(batch, getSentryErrorLogsCmd())
+			batch = append(batch, getSentryErrorLogsCmd(m.cfg.Sentry, m.sentryQuery, m.sentrySort), getSentryProjectVolumeCmd(m.cfg.Sentry))
 		}
 		batch = append(batch, tickCmd())
 		return m, tea.Batch(batch...)
@@ -155,6 +902,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// Switch the Sentry pane to query and refresh it right away
+func (m model) applySentryQuery(query string) (tea.Model, tea.Cmd) {
+	if query == "" {
+		query = m.cfg.Sentry.DefaultQuery
+	}
+	m.sentryQuery = query
+	m.selectedIssue = 0
+	return m, getSentryErrorLogsCmd(m.cfg.Sentry, m.sentryQuery, m.sentrySort)
+}
+
 // Helper to emit a WindowSizeMsg as a command
 func sendWindowSizeCmd(width, height int) tea.Cmd {
 	return func() tea.Msg { return tea.WindowSizeMsg{Width: width, Height: height} }
@@ -190,6 +947,36 @@ func (m model) View() string {
 	if m.showLogViewer {
 		return m.logViewer.View()
 	}
+	if m.showTimeline {
+		return m.timelineView()
+	}
+	if m.showNotes {
+		return m.notesView()
+	}
+	if m.showHandoff {
+		return m.handoffView()
+	}
+	if m.showPodDetail {
+		return m.podDetailView()
+	}
+	if m.showWorkloads {
+		return m.workloadsView()
+	}
+	if m.podAction != nil {
+		return m.podActionView()
+	}
+	if m.execTarget != nil {
+		return m.execPickerView()
+	}
+	if m.forwardOptions != nil {
+		return m.forwardPickerView()
+	}
+	if m.showForwards {
+		return m.forwardsView()
+	}
+	if m.showHelp {
+		return m.helpView()
+	}
 
 	basePaneStyle := lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder()).
@@ -220,34 +1007,82 @@ func (m model) View() string {
 		ctxSuffix = " [" + levelInfoStyle.Render(m.currentKubeContext) + "]"
 	}
 
-	pane1Content := paneTitleStyle.Render("🛑 Recent Sentry Errors") + "\n" + m.sentryLogs
-	pane2Content := paneTitleStyle.Render("📊 Analytics") + "\n" + m.sentryStats + "\n\n" + m.apiResponseTimes
-	pane3Content := paneTitleStyle.Render("📦 Pod Status (Live)"+ctxSuffix) + "\n" + colorizeKubectlPodsWithSelection(m.kubectlPods, m.selectedPodIndex)
-	pane4Content := "^Q: Quit | ^C: Exit | ?: Help | Tab/Shift+Tab: Switch Panes"
-
-	var pane1, pane2, pane3 string
+	selectedIssue := -1
 	if m.selectedPane == 0 {
-		pane1 = focusedPaneStyle.Width(targetHalfWidthContent).Height(targetHalfHeightContent).Render(pane1Content)
-		pane2 = basePaneStyle.Width(targetHalfWidthContent).Height(targetHalfHeightContent).Render(pane2Content)
-		pane3 = basePaneStyle.Width(targetHalfWidthContent).Height(availableHeightForTopPanes - (basePaneStyle.GetVerticalPadding() * 2) - (basePaneStyle.GetVerticalBorderSize() * 2)).Render(pane3Content)
-	} else if m.selectedPane == 1 {
-		pane1 = basePaneStyle.Width(targetHalfWidthContent).Height(targetHalfHeightContent).Render(pane1Content)
-		pane2 = focusedPaneStyle.Width(targetHalfWidthContent).Height(targetHalfHeightContent).Render(pane2Content)
-		pane3 = basePaneStyle.Width(targetHalfWidthContent).Height(availableHeightForTopPanes - (basePaneStyle.GetVerticalPadding() * 2) - (basePaneStyle.GetVerticalBorderSize() * 2)).Render(pane3Content)
-	} else if m.selectedPane == 2 {
-		pane1 = basePaneStyle.Width(targetHalfWidthContent).Height(targetHalfHeightContent).Render(pane1Content)
-		pane2 = basePaneStyle.Width(targetHalfWidthContent).Height(targetHalfHeightContent).Render(pane2Content)
-		pane3 = focusedPaneStyle.Width(targetHalfWidthContent).Height(availableHeightForTopPanes - (basePaneStyle.GetVerticalPadding() * 2) - (basePaneStyle.GetVerticalBorderSize() * 2)).Render(pane3Content)
+		selectedIssue = m.selectedIssue
+	}
+	sentryTitle := "🛑 Recent Sentry Errors"
+	newCount, regressedCount := countBadgedIssues(m.sentryIssues)
+	if newCount > 0 || regressedCount > 0 {
+		sentryTitle += fmt.Sprintf(" (%d new, %d regressed)", newCount, regressedCount)
+	}
+	selectedHealthRow := -1
+	if m.selectedPane == 1 {
+		selectedHealthRow = m.selectedHealthRow
+	}
+	queryLine := logViewerFooterStyle.Render("Query: " + m.sentryQuery + " | Sort: " + m.sentrySort)
+	if m.editingQuery {
+		queryLine = m.queryInput.View()
+	}
+	pane1Content := paneTitleStyle.Render(sentryTitle) + "\n" + queryLine + "\n" + renderSentryIssues(m.sentryProjects, m.sentryIssues, selectedIssue)
+	pane2Content := paneTitleStyle.Render("📊 Analytics") + "\n" + renderSentryStats(m.cfg.Sentry.Projects, m.sentryStats, m.sentryVolume) + "\n\n" + renderEndpointResults(m.apiResponseTimes, selectedHealthRow, m.healthToggled, m.sloStatuses, m.probeHistory)
+	podsTitle := "📦 Pod Status (Live)" + ctxSuffix
+	if m.podMetricsErr != "" {
+		// usually metrics-server is not installed
+		podsTitle += " · no metrics"
 	}
+	pane3Content := paneTitleStyle.Render(podsTitle) + "\n"
+	if deploying := renderDeploying(m.workloads); deploying != "" {
+		pane3Content += deploying + "\n"
+	}
+	pane3Content += colorizeKubectlPodsWithSelection(withUsageColumns(m.kubectlPods, m.podNames, m.podUsageSamples, m.podHighUsage), m.selectedPodIndex)
+	selectedEvent := -1
+	if m.selectedPane == 3 {
+		selectedEvent = m.selectedEvent
+	}
+	eventsTitle := "⚠️ Warning Events"
+	if len(m.warningEvents) > 0 {
+		eventsTitle += fmt.Sprintf(" (%d objects)", len(m.warningEvents))
+	}
+	eventsContent := paneTitleStyle.Render(eventsTitle) + "\n" + renderWarningEvents(m.warningEvents, m.warningEventsErr, selectedEvent, m.currentNamespace, targetHalfWidthContent-basePaneStyle.GetHorizontalPadding())
+	pane4Content := m.keyHintLine()
+	if m.statusMessage != "" {
+		pane4Content += "\n" + levelInfoStyle.Render(m.statusMessage)
+	}
+
+	paneStyle := func(pane int) lipgloss.Style {
+		if pane == m.selectedPane {
+			return focusedPaneStyle
+		}
+		return basePaneStyle
+	}
+	pane1 := paneStyle(0).Width(targetHalfWidthContent).Height(targetHalfHeightContent).Render(pane1Content)
+	pane2 := paneStyle(1).Width(targetHalfWidthContent).Height(targetHalfHeightContent).Render(pane2Content)
+	pane3 := paneStyle(2).Width(targetHalfWidthContent).Height(targetHalfHeightContent).Render(pane3Content)
+	eventsPane := paneStyle(3).Width(targetHalfWidthContent).Height(targetHalfHeightContent).Render(eventsContent)
 
 	pane4 := basePaneStyle.Width(m.width - (basePaneStyle.GetHorizontalPadding() * 2) - (basePaneStyle.GetHorizontalBorderSize() * 2)).Height(keyHintsContentHeight).Render(pane4Content)
 
 	leftColumn := lipgloss.JoinVertical(lipgloss.Top, pane1, pane2)
-	topSection := lipgloss.JoinHorizontal(lipgloss.Top, leftColumn, pane3)
+	rightColumn := lipgloss.JoinVertical(lipgloss.Top, pane3, eventsPane)
+	topSection := lipgloss.JoinHorizontal(lipgloss.Top, leftColumn, rightColumn)
 
 	return lipgloss.JoinVertical(lipgloss.Left, topSection, pane4)
 }
 
+// recordHistory adds records to the timeline and returns the command that
+// persists them
+func (m *model) recordHistory(records []historyRecord) tea.Cmd {
+	m.timeline.add(records)
+	if m.showTimeline {
+		m.refreshTimeline()
+	}
+	if m.handoffData != nil {
+		m.handoffData.records = append(m.handoffData.records, records...)
+	}
+	return writeHistoryCmd(m.history, records)
+}
+
 func countNonEmptyLines(output string) int {
 	lines := strings.Split(output, "\n")
 	count := 0
@@ -270,8 +1105,82 @@ func splashTimerCmd() tea.Cmd {
 }
 
 func main() {
-	p := tea.NewProgram(model{showSplash: true}, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	cfg, err := loadConfig(configPath())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "handoff" {
+		handoffMain(cfg, os.Args[2:])
+	}
+	// Reload the stored history; probe samples beyond the SLO windows are
+	// trimmed again by the first probe round
+	history := openHistoryStore(historyDir(), cfg.HistoryRetentionDays)
+	probes := newProbeHistory()
+	sentryCounts, podStatuses, podRestarts := map[string]int{}, map[string]map[string]string{}, map[string]map[string]int{}
+	records := history.load(time.Now().Add(-history.retention))
+	restoreHistory(records, probes, sentryCounts, podStatuses, podRestarts)
+	notes := loadNotes(notesDir())
+	deployRevisions, deploys := restoreDeploys(records)
+	events := newTimeline()
+	events.add(records)
+	events.addNotes(notes)
+
+	noteInput := textarea.New()
+	noteInput.Placeholder = "What happened? Ctrl+S saves the note with the current time."
+	noteInput.ShowLineNumbers = false
+	noteInput.SetHeight(5)
+	noteSearch := textinput.New()
+	noteSearch.Prompt = "Search notes: "
+
+	replicasInput := textinput.New()
+	replicasInput.Prompt = "Replicas: "
+	replicasInput.CharLimit = 4
+
+	localPortInput := textinput.New()
+	localPortInput.Prompt = "Local port: "
+	localPortInput.Placeholder = "auto"
+	localPortInput.CharLimit = 5
+
+	queryInput := textinput.New()
+	queryInput.Prompt = "Query: "
+	queryInput.Placeholder = "is:unresolved level:error"
+	p := tea.NewProgram(model{
+		cfg:           cfg,
+		sentryQuery:   cfg.Sentry.DefaultQuery,
+		sentrySort:    sentrySortKeys[0],
+		queryInput:    queryInput,
+		showSplash:    true,
+		firingAlerts:  map[string]bool{},
+		healthToggled: map[string]bool{},
+		probeHistory:  probes,
+		history:       history,
+		timeline:      events,
+		notes:         notes,
+		noteInput:     noteInput,
+		noteSearch:    noteSearch,
+		replicasInput: replicasInput,
+		sentryStats:   sentryCounts,
+		seenIssues:    loadSeenIssueStore(seenIssuesPath()),
+
+		localPortInput: localPortInput,
+		forwardEvents:  make(chan portForwardMsg, 16),
+		forwardsDone:   make(chan struct{}),
+
+		warningWatchCh: make(chan warningWatchMsg, 64),
+
+		workloadExpanded: map[string]bool{},
+		deployRevisions:  deployRevisions,
+		deploys:          deploys,
+
+		scopedPodStatuses: podStatuses,
+		scopedPodRestarts: podRestarts,
+	}, tea.WithAltScreen())
+	final, err := p.Run()
+	if m, ok := final.(model); ok {
+		m.stopPortForwards()
+		m.stopWarningWatch()
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
 }
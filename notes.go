@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// incidentNote is one timestamped scratchpad entry, optionally linked to the
+// Sentry issue or pod that was selected when it was written
+type incidentNote struct {
+	Time  time.Time `json:"t"`
+	Text  string    `json:"text"`
+	Issue string    `json:"issue,omitempty"` // short ID
+	Pod   string    `json:"pod,omitempty"`
+}
+
+// Notes are kept in one JSON Lines file per day and, unlike the history,
+// never expire.
+func notesDir() string {
+	return filepath.Join(stateDir(), "notes")
+}
+
+func loadNotes(dir string) []incidentNote {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	var notes []incidentNote
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var n incidentNote
+			if json.Unmarshal(scanner.Bytes(), &n) == nil {
+				notes = append(notes, n)
+			}
+		}
+		f.Close()
+	}
+	sort.SliceStable(notes, func(i, j int) bool { return notes[i].Time.Before(notes[j].Time) })
+	return notes
+}
+
+func appendNote(dir string, note incidentNote) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, note.Time.Format("2006-01-02")+".jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data, err := json.Marshal(note)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// "[ABC-12]", "[pod web-1]" or ""
+func (n incidentNote) link() string {
+	switch {
+	case n.Issue != "":
+		return "[" + n.Issue + "]"
+	case n.Pod != "":
+		return "[pod " + n.Pod + "]"
+	}
+	return ""
+}
+
+// Single line form for the timeline and reports
+func (n incidentNote) summary() string {
+	text := strings.Join(strings.Fields(strings.ReplaceAll(n.Text, "\n", " / ")), " ")
+	if link := n.link(); link != "" {
+		return link + " " + text
+	}
+	return text
+}
+
+// searchNotes returns the notes whose text or link contains query, ignoring case
+func searchNotes(notes []incidentNote, query string) []incidentNote {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return notes
+	}
+	var found []incidentNote
+	for _, n := range notes {
+		if strings.Contains(strings.ToLower(n.Text+" "+n.Issue+" "+n.Pod), query) {
+			found = append(found, n)
+		}
+	}
+	return found
+}
+
+// noteLink is what a new note would be linked to: the selected Sentry issue
+// or pod, depending on the focused pane
+func (m model) noteLink() (issue, pod string) {
+	switch m.selectedPane {
+	case 0:
+		if m.selectedIssue < len(m.sentryIssues) {
+			return m.sentryIssues[m.selectedIssue].ShortID, ""
+		}
+	case 2:
+		if m.selectedPodIndex < len(m.podNames) {
+			return "", m.podNames[m.selectedPodIndex]
+		}
+	}
+	return "", ""
+}
+
+func (m model) notesView() string {
+	link := (incidentNote{Issue: m.noteIssue, Pod: m.notePod}).link()
+	title := "Incident notes"
+	switch {
+	case link != "" && m.noteLinked:
+		title += " · linking to " + link
+	case link != "":
+		title += " · not linked"
+	}
+	header := logViewerHeaderStyle.Render(title)
+
+	var footerText string
+	if m.searchingNotes {
+		footerText = "Enter/Esc: Done Searching"
+	} else {
+		footerText = "Ctrl+S: Save Note | Ctrl+L: Link/Unlink | Ctrl+F: Search | Esc: Back"
+	}
+	footer := logViewerFooterStyle.Render(footerText)
+	search := ""
+	if m.searchingNotes || m.noteSearch.Value() != "" {
+		search = m.noteSearch.View()
+	}
+
+	editor := m.noteInput.View()
+	listHeight := m.height - lipgloss.Height(header) - lipgloss.Height(footer) - lipgloss.Height(editor) - 1
+	if search != "" {
+		listHeight--
+	}
+	var lines []string
+	for _, n := range searchNotes(m.notes, m.noteSearch.Value()) {
+		prefix := n.Time.Local().Format("Jan 02 15:04") + "  "
+		if link := n.link(); link != "" {
+			prefix += issueIDStyle.Render(link) + " "
+		}
+		for i, line := range strings.Split(n.Text, "\n") {
+			if i == 0 {
+				lines = append(lines, prefix+line)
+			} else {
+				lines = append(lines, "              "+line)
+			}
+		}
+	}
+	if len(lines) == 0 && m.noteSearch.Value() != "" {
+		lines = []string{logViewerFooterStyle.Render("No matching notes")}
+	} else if len(lines) == 0 {
+		lines = []string{logViewerFooterStyle.Render("No notes yet")}
+	}
+	if listHeight > 0 && len(lines) > listHeight {
+		lines = lines[len(lines)-listHeight:]
+	}
+	parts := []string{header}
+	if search != "" {
+		parts = append(parts, search)
+	}
+	parts = append(parts, strings.Join(lines, "\n"), "", editor, footer)
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+func (m *model) saveNote() {
+	text := strings.TrimSpace(m.noteInput.Value())
+	if text == "" {
+		return
+	}
+	note := incidentNote{Time: time.Now(), Text: text}
+	if m.noteLinked {
+		note.Issue, note.Pod = m.noteIssue, m.notePod
+	}
+	if err := appendNote(notesDir(), note); err != nil {
+		m.statusMessage = fmt.Sprintf("Failed to save note: %v", err)
+		return
+	}
+	m.notes = append(m.notes, note)
+	m.timeline.addNotes([]incidentNote{note})
+	if m.showTimeline {
+		m.refreshTimeline()
+	}
+	m.noteInput.Reset()
+	m.statusMessage = "Note saved"
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// Pod actions, by key in the pods pane
+var podActionKeys = map[string]string{
+	"D": "delete",
+	"R": "restart",
+	"S": "scale",
+	"C": "cordon",
+}
+
+// podActionTarget is everything an action needs, resolved before the
+// confirmation so the modal shows exactly what will be changed. Context and
+// namespace are passed to kubectl explicitly, so switching context while the
+// modal is open cannot redirect the action.
+type podActionTarget struct {
+	Action    string
+	Pod       string
+	Context   string
+	Namespace string
+	Kind      string // owning workload: Deployment, StatefulSet, ...
+	Workload  string
+	Replicas  int
+	Node      string
+	Container string // exec only
+}
+
+type podActionTargetMsg struct {
+	target podActionTarget
+	err    error
+}
+
+type podActionDoneMsg struct {
+	target podActionTarget
+	args   []string
+	output string
+	err    error
+}
+
+func kubectlIn(kubeContext, namespace string, args ...string) *exec.Cmd {
+	return kubectlInCtx(context.Background(), kubeContext, namespace, args...)
+}
+
+// kubectlInCtx is kubectlIn killed when ctx is done
+func kubectlInCtx(ctx context.Context, kubeContext, namespace string, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, "kubectl", append([]string{"--context", kubeContext, "--namespace", namespace}, args...)...)
+}
+
+// kubectlOutput runs kubectl and returns its stdout; errors carry stderr
+func kubectlOutput(kubeContext, namespace string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := kubectlIn(kubeContext, namespace, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("kubectl %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("kubectl %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+func kubectlJSONPath(kubeContext, namespace, resource, jsonPath string) (string, error) {
+	out, err := kubectlIn(kubeContext, namespace, "get", resource, "-o", "jsonpath="+jsonPath).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("kubectl get %s: %s", resource, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// resolvePodActionCmd finds the node and owning workload of pod. Pods of a
+// Deployment are owned through a ReplicaSet, which is followed one level up.
+func resolvePodActionCmd(action, pod, kubeContext, namespace string) tea.Cmd {
+	return func() tea.Msg {
+		t := podActionTarget{Action: action, Pod: pod, Context: kubeContext, Namespace: namespace}
+		info, err := kubectlJSONPath(kubeContext, namespace, "pod/"+pod, "{.spec.nodeName} {.metadata.ownerReferences[0].kind}/{.metadata.ownerReferences[0].name}")
+		if err != nil {
+			return podActionTargetMsg{err: err}
+		}
+		node, owner, _ := strings.Cut(info, " ")
+		t.Node = node
+		t.Kind, t.Workload, _ = strings.Cut(owner, "/")
+		if t.Kind == "ReplicaSet" {
+			owner, err := kubectlJSONPath(kubeContext, namespace, "replicaset/"+t.Workload, "{.metadata.ownerReferences[0].kind}/{.metadata.ownerReferences[0].name}")
+			if err != nil {
+				return podActionTargetMsg{err: err}
+			}
+			if kind, name, _ := strings.Cut(owner, "/"); kind != "" {
+				t.Kind, t.Workload = kind, name
+			}
+		}
+
+		switch action {
+		case "restart":
+			if t.Kind != "Deployment" && t.Kind != "StatefulSet" && t.Kind != "DaemonSet" {
+				return podActionTargetMsg{err: fmt.Errorf("%s is not owned by a Deployment, StatefulSet or DaemonSet", pod)}
+			}
+		case "scale":
+			if t.Kind != "Deployment" && t.Kind != "StatefulSet" && t.Kind != "ReplicaSet" {
+				return podActionTargetMsg{err: fmt.Errorf("%s is not owned by a scalable workload", pod)}
+			}
+			replicas, err := kubectlJSONPath(kubeContext, namespace, strings.ToLower(t.Kind)+"/"+t.Workload, "{.spec.replicas}")
+			if err != nil {
+				return podActionTargetMsg{err: err}
+			}
+			t.Replicas, _ = strconv.Atoi(replicas)
+		case "cordon":
+			if t.Node == "" {
+				return podActionTargetMsg{err: fmt.Errorf("%s is not scheduled on a node", pod)}
+			}
+		}
+		return podActionTargetMsg{target: t}
+	}
+}
+
+// describe is the one-line summary used in the modal, the audit log and the
+// timeline
+func (t podActionTarget) describe() string {
+	switch t.Action {
+	case "delete":
+		return "Delete pod " + t.Pod
+	case "restart":
+		return fmt.Sprintf("Rollout restart %s/%s", strings.ToLower(t.Kind), t.Workload)
+	case "scale":
+		return fmt.Sprintf("Scale %s/%s to %d replicas", strings.ToLower(t.Kind), t.Workload, t.Replicas)
+	case "cordon":
+		return "Cordon node " + t.Node
+	case "exec":
+		return fmt.Sprintf("Shell in pod %s container %s", t.Pod, t.Container)
+	}
+	return t.Action
+}
+
+func (t podActionTarget) kubectlArgs() []string {
+	switch t.Action {
+	case "delete":
+		return []string{"delete", "pod", t.Pod}
+	case "restart":
+		return []string{"rollout", "restart", strings.ToLower(t.Kind) + "/" + t.Workload}
+	case "scale":
+		return []string{"scale", strings.ToLower(t.Kind) + "/" + t.Workload, fmt.Sprintf("--replicas=%d", t.Replicas)}
+	case "cordon":
+		return []string{"cordon", t.Node}
+	}
+	return nil
+}
+
+func runPodActionCmd(t podActionTarget) tea.Cmd {
+	return func() tea.Msg {
+		args := t.kubectlArgs()
+		if args == nil {
+			return podActionDoneMsg{target: t, err: errors.New("unknown action " + t.Action)}
+		}
+		out, err := kubectlIn(t.Context, t.Namespace, args...).CombinedOutput()
+		return podActionDoneMsg{target: t, args: args, output: strings.TrimSpace(string(out)), err: err}
+	}
+}
+
+// auditEntry is one line of audit.log in the state directory
+type auditEntry struct {
+	Time      time.Time `json:"time"`
+	User      string    `json:"user"`
+	Context   string    `json:"context"`
+	Namespace string    `json:"namespace"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target"`
+	Command   string    `json:"command"`
+	Result    string    `json:"result"` // "ok" or "error"
+	Output    string    `json:"output"`
+}
+
+func auditLogPath() string {
+	return filepath.Join(stateDir(), "audit.log")
+}
+
+func writeAuditEntry(msg podActionDoneMsg) (auditEntry, error) {
+	entry := auditEntry{
+		Time:      time.Now(),
+		Context:   msg.target.Context,
+		Namespace: msg.target.Namespace,
+		Action:    msg.target.Action,
+		Target:    msg.target.describe(),
+		Command:   "kubectl " + strings.Join(msg.args, " "),
+		Result:    "ok",
+		Output:    msg.output,
+	}
+	if u, err := user.Current(); err == nil {
+		entry.User = u.Username
+	}
+	if msg.err != nil {
+		entry.Result = "error"
+		if entry.Output == "" {
+			entry.Output = msg.err.Error()
+		}
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return entry, err
+	}
+	if err := os.MkdirAll(filepath.Dir(auditLogPath()), 0o755); err != nil {
+		return entry, err
+	}
+	f, err := os.OpenFile(auditLogPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return entry, err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return entry, err
+}
+
+// loadAuditLog reads the audit log entries newer than since; a missing log
+// has no entries
+func loadAuditLog(since time.Time) ([]auditEntry, error) {
+	f, err := os.Open(auditLogPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+	var entries []auditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e auditEntry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil && !e.Time.Before(since) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// writeAudit appends to the audit log and to the cached handoff
+func (m *model) writeAudit(msg podActionDoneMsg) error {
+	entry, err := writeAuditEntry(msg)
+	if err == nil && m.handoffData != nil {
+		m.handoffData.actions = append(m.handoffData.actions, entry)
+	}
+	return err
+}
+
+func (m model) podActionView() string {
+	t := m.podAction
+	title := t.describe()
+	if t.Action == "scale" {
+		title = fmt.Sprintf("Scale %s/%s", strings.ToLower(t.Kind), t.Workload)
+	}
+	lines := []string{
+		headerStyle.Render(title),
+		"",
+		fmt.Sprintf("Context:   %s", pendingStyle.Render(t.Context)),
+		fmt.Sprintf("Namespace: %s", pendingStyle.Render(t.Namespace)),
+		fmt.Sprintf("Pod:       %s", t.Pod),
+	}
+	if t.Workload != "" {
+		lines = append(lines, fmt.Sprintf("Workload:  %s/%s", t.Kind, t.Workload))
+	}
+	if t.Node != "" {
+		lines = append(lines, fmt.Sprintf("Node:      %s", t.Node))
+	}
+	lines = append(lines, "")
+	if t.Action == "scale" {
+		lines = append(lines, fmt.Sprintf("Currently %d replicas", t.Replicas), m.replicasInput.View(), "", "Enter: Scale | Esc: Cancel")
+	} else {
+		lines = append(lines, "y: Confirm | n/Esc: Cancel")
+	}
+	return m.modalView(lines)
+}
+
+// modalView centres a bordered box over the whole screen
+func (m model) modalView(lines []string) string {
+	box := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("9")).Padding(1, 2).Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// startPodAction looks up the selected pod's workload and node; the modal
+// opens once podActionTargetMsg arrives. The action runs with the context
+// checked for read-only here, even if the current one changes meanwhile.
+func (m model) startPodAction(action string) (tea.Model, tea.Cmd) {
+	if m.selectedPodIndex >= len(m.podNames) {
+		return m, nil
+	}
+	if m.cfg.readOnly(m.currentKubeContext) {
+		m.statusMessage = "Read-only mode: pod actions are disabled for " + m.currentKubeContext
+		return m, nil
+	}
+	if m.currentKubeContext == "" || m.currentNamespace == "" {
+		m.statusMessage = "Kube context not known yet"
+		return m, nil
+	}
+	pod := m.podNames[m.selectedPodIndex]
+	m.statusMessage = "Looking up " + pod + "..."
+	return m, resolvePodActionCmd(action, pod, m.currentKubeContext, m.currentNamespace)
+}
+
+func (m model) confirmPodAction() (tea.Model, tea.Cmd) {
+	t := *m.podAction
+	if t.Action == "scale" {
+		replicas, err := strconv.Atoi(strings.TrimSpace(m.replicasInput.Value()))
+		if err != nil || replicas < 0 {
+			m.statusMessage = "Replicas must be a number ≥ 0"
+			return m, nil
+		}
+		t.Replicas = replicas
+		m.replicasInput.Blur()
+	}
+	m.podAction = nil
+	m.statusMessage = t.describe() + "..."
+	return m, runPodActionCmd(t)
+}
+
+// First line of kubectl's output, or the error when there was none
+func firstLine(output string, err error) string {
+	if line, _, _ := strings.Cut(output, "\n"); line != "" {
+		return line
+	}
+	return err.Error()
+}
@@ -0,0 +1,406 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// The subset of the Pod API object shown in the detail view
+type kubePod struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec struct {
+		NodeName       string          `json:"nodeName"`
+		InitContainers []kubeContainer `json:"initContainers"`
+		Containers     []kubeContainer `json:"containers"`
+	} `json:"spec"`
+	Status struct {
+		Phase      string    `json:"phase"`
+		Reason     string    `json:"reason"`
+		Message    string    `json:"message"`
+		QOSClass   string    `json:"qosClass"`
+		PodIP      string    `json:"podIP"`
+		StartTime  time.Time `json:"startTime"`
+		Conditions []struct {
+			Type               string    `json:"type"`
+			Status             string    `json:"status"`
+			Reason             string    `json:"reason"`
+			Message            string    `json:"message"`
+			LastTransitionTime time.Time `json:"lastTransitionTime"`
+		} `json:"conditions"`
+		InitContainerStatuses []kubeContainerStatus `json:"initContainerStatuses"`
+		ContainerStatuses     []kubeContainerStatus `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+type kubeContainer struct {
+	Name      string `json:"name"`
+	Image     string `json:"image"`
+	Resources struct {
+		Requests map[string]string `json:"requests"`
+		Limits   map[string]string `json:"limits"`
+	} `json:"resources"`
+	LivenessProbe  *kubeProbe `json:"livenessProbe"`
+	ReadinessProbe *kubeProbe `json:"readinessProbe"`
+	StartupProbe   *kubeProbe `json:"startupProbe"`
+}
+
+type kubeProbe struct {
+	HTTPGet *struct {
+		Path   string `json:"path"`
+		Port   any    `json:"port"` // number or named port
+		Scheme string `json:"scheme"`
+	} `json:"httpGet"`
+	TCPSocket *struct {
+		Port any `json:"port"`
+	} `json:"tcpSocket"`
+	Exec *struct {
+		Command []string `json:"command"`
+	} `json:"exec"`
+	GRPC *struct {
+		Port    int    `json:"port"`
+		Service string `json:"service"`
+	} `json:"grpc"`
+	InitialDelaySeconds int `json:"initialDelaySeconds"`
+	TimeoutSeconds      int `json:"timeoutSeconds"`
+	PeriodSeconds       int `json:"periodSeconds"`
+	FailureThreshold    int `json:"failureThreshold"`
+}
+
+type kubeContainerStatus struct {
+	Name         string             `json:"name"`
+	Ready        bool               `json:"ready"`
+	RestartCount int                `json:"restartCount"`
+	State        kubeContainerState `json:"state"`
+	LastState    kubeContainerState `json:"lastState"`
+}
+
+type kubeContainerState struct {
+	Waiting *struct {
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	} `json:"waiting"`
+	Running *struct {
+		StartedAt time.Time `json:"startedAt"`
+	} `json:"running"`
+	Terminated *struct {
+		Reason     string    `json:"reason"`
+		Message    string    `json:"message"`
+		ExitCode   int       `json:"exitCode"`
+		Signal     int       `json:"signal"`
+		FinishedAt time.Time `json:"finishedAt"`
+	} `json:"terminated"`
+}
+
+// kubeEvent is a core/v1 Event reduced to what is displayed
+type kubeEvent struct {
+	Type      string // "Normal" or "Warning"
+	Reason    string
+	Message   string
+	Count     int
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Kind      string // involved object
+	Name      string
+	Namespace string
+}
+
+// kubeEventObject is a core/v1 Event as listed or watched
+type kubeEventObject struct {
+	Metadata struct {
+		UID               string    `json:"uid"`
+		CreationTimestamp time.Time `json:"creationTimestamp"`
+	} `json:"metadata"`
+	Type           string    `json:"type"`
+	Reason         string    `json:"reason"`
+	Message        string    `json:"message"`
+	Count          int       `json:"count"`
+	FirstTimestamp time.Time `json:"firstTimestamp"`
+	LastTimestamp  time.Time `json:"lastTimestamp"`
+	EventTime      time.Time `json:"eventTime"`
+	Series         *struct {
+		Count            int       `json:"count"`
+		LastObservedTime time.Time `json:"lastObservedTime"`
+	} `json:"series"`
+	InvolvedObject struct {
+		Kind      string `json:"kind"`
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"involvedObject"`
+}
+
+// event reduces the object. Events written through events.k8s.io only set
+// eventTime and series, so those are used when the legacy timestamps are
+// missing.
+func (item kubeEventObject) event() kubeEvent {
+	e := kubeEvent{
+		Type:      item.Type,
+		Reason:    item.Reason,
+		Message:   strings.TrimSpace(item.Message),
+		Count:     item.Count,
+		FirstSeen: item.FirstTimestamp,
+		LastSeen:  item.LastTimestamp,
+		Kind:      item.InvolvedObject.Kind,
+		Name:      item.InvolvedObject.Name,
+		Namespace: item.InvolvedObject.Namespace,
+	}
+	if item.Series != nil {
+		e.Count = item.Series.Count
+		if e.LastSeen.IsZero() {
+			e.LastSeen = item.Series.LastObservedTime
+		}
+	}
+	for _, t := range []time.Time{item.EventTime, item.Metadata.CreationTimestamp} {
+		if e.FirstSeen.IsZero() {
+			e.FirstSeen = t
+		}
+		if e.LastSeen.IsZero() {
+			e.LastSeen = t
+		}
+	}
+	if e.Count == 0 {
+		e.Count = 1
+	}
+	return e
+}
+
+// parseKubeEvents reads the output of `kubectl get events -o json`
+func parseKubeEvents(data []byte) ([]kubeEvent, error) {
+	var list struct {
+		Items []kubeEventObject `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	events := make([]kubeEvent, 0, len(list.Items))
+	for _, item := range list.Items {
+		events = append(events, item.event())
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].LastSeen.Before(events[j].LastSeen) })
+	return events, nil
+}
+
+type podDetailMsg struct {
+	pod    string
+	detail string
+	err    error
+}
+
+// getPodDetailCmd fetches the pod and its events and renders them
+func getPodDetailCmd(pod, kubeContext, namespace string) tea.Cmd {
+	return func() tea.Msg {
+		out, err := kubectlOutput(kubeContext, namespace, "get", "pod", pod, "-o", "json")
+		if err != nil {
+			return podDetailMsg{pod: pod, err: err}
+		}
+		var p kubePod
+		if err := json.Unmarshal(out, &p); err != nil {
+			return podDetailMsg{pod: pod, err: err}
+		}
+		var events []kubeEvent
+		eventsErr := ""
+		out, err = kubectlOutput(kubeContext, namespace, "get", "events", "--field-selector", "involvedObject.kind=Pod,involvedObject.name="+pod, "-o", "json")
+		if err == nil {
+			events, err = parseKubeEvents(out)
+		}
+		if err != nil {
+			eventsErr = err.Error()
+		}
+		return podDetailMsg{pod: pod, detail: renderPodDetail(p, events, eventsErr, time.Now())}
+	}
+}
+
+func ago(t, now time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return formatDuration(now.Sub(t)) + " ago"
+}
+
+func renderPodDetail(p kubePod, events []kubeEvent, eventsErr string, now time.Time) string {
+	var b strings.Builder
+	section := func(title string) { fmt.Fprintf(&b, "\n%s\n", headerStyle.Render(title)) }
+
+	phase := p.Status.Phase
+	if p.Status.Reason != "" {
+		phase += " (" + p.Status.Reason + ")"
+	}
+	node := p.Spec.NodeName
+	if node == "" {
+		node = pendingStyle.Render("not scheduled")
+	}
+	fmt.Fprintf(&b, "%s · %s · node %s · QoS %s · IP %s · started %s\n", p.Metadata.Name, phase, node, p.Status.QOSClass, orDash(p.Status.PodIP), ago(p.Status.StartTime, now))
+	if p.Status.Message != "" {
+		fmt.Fprintf(&b, "%s\n", statusUnresolvedStyle.Render(p.Status.Message))
+	}
+
+	section("Conditions")
+	for _, c := range p.Status.Conditions {
+		line := fmt.Sprintf("  %-26s %-6s %s", c.Type, c.Status, ago(c.LastTransitionTime, now))
+		if c.Status != "True" {
+			line = pendingStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+		if c.Reason != "" || c.Message != "" {
+			fmt.Fprintf(&b, "    %s %s\n", c.Reason, c.Message)
+		}
+	}
+
+	statuses := map[string]kubeContainerStatus{}
+	for _, s := range append(p.Status.InitContainerStatuses, p.Status.ContainerStatuses...) {
+		statuses[s.Name] = s
+	}
+	section("Containers")
+	for i, c := range append(p.Spec.InitContainers, p.Spec.Containers...) {
+		s := statuses[c.Name]
+		kind := ""
+		if i < len(p.Spec.InitContainers) {
+			kind = " (init)"
+		}
+		ready := statusResolvedStyle.Render("ready")
+		if !s.Ready {
+			ready = statusUnresolvedStyle.Render("not ready")
+		}
+		fmt.Fprintf(&b, "  %s%s · %s · %s · %d restarts\n", c.Name, kind, c.Image, ready, s.RestartCount)
+		fmt.Fprintf(&b, "    State: %s\n", describeContainerState(s.State, now))
+		if last := describeContainerState(s.LastState, now); last != "-" {
+			fmt.Fprintf(&b, "    Last termination: %s\n", last)
+		}
+		fmt.Fprintf(&b, "    Requests: %s · Limits: %s\n", formatResources(c.Resources.Requests), formatResources(c.Resources.Limits))
+		for _, probe := range []struct {
+			name  string
+			probe *kubeProbe
+		}{{"Liveness", c.LivenessProbe}, {"Readiness", c.ReadinessProbe}, {"Startup", c.StartupProbe}} {
+			if probe.probe != nil {
+				fmt.Fprintf(&b, "    %s: %s\n", probe.name, probe.probe.describe())
+			}
+		}
+	}
+
+	section("Events")
+	if eventsErr != "" {
+		fmt.Fprintf(&b, "  %s\n", statusUnresolvedStyle.Render("unavailable: "+eventsErr))
+	} else if len(events) == 0 {
+		b.WriteString("  No events (events expire after about an hour)\n")
+	}
+	for _, e := range events {
+		line := fmt.Sprintf("  %s  %-7s %-20s x%-3d %s", e.LastSeen.Local().Format("Jan 02 15:04:05"), e.Type, e.Reason, e.Count, e.Message)
+		if e.Type == "Warning" {
+			line = statusUnresolvedStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func describeContainerState(s kubeContainerState, now time.Time) string {
+	switch {
+	case s.Waiting != nil:
+		text := "Waiting: " + s.Waiting.Reason
+		if s.Waiting.Message != "" {
+			text += " – " + s.Waiting.Message
+		}
+		if podLevel(s.Waiting.Reason) == "error" {
+			return statusUnresolvedStyle.Render(text)
+		}
+		return pendingStyle.Render(text)
+	case s.Running != nil:
+		return statusResolvedStyle.Render("Running since " + ago(s.Running.StartedAt, now))
+	case s.Terminated != nil:
+		t := s.Terminated
+		text := fmt.Sprintf("Terminated: %s, exit code %d", t.Reason, t.ExitCode)
+		if t.Signal != 0 {
+			text += fmt.Sprintf(", signal %d", t.Signal)
+		}
+		text += ", finished " + ago(t.FinishedAt, now)
+		if t.Message != "" {
+			text += " – " + strings.TrimSpace(t.Message)
+		}
+		if t.ExitCode != 0 {
+			return statusUnresolvedStyle.Render(text)
+		}
+		return text
+	}
+	return "-"
+}
+
+// "cpu=100m memory=128Mi", sorted by resource name
+func formatResources(r map[string]string) string {
+	if len(r) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + r[name]
+	}
+	return strings.Join(parts, " ")
+}
+
+func (p kubeProbe) describe() string {
+	var action string
+	switch {
+	case p.HTTPGet != nil:
+		action = fmt.Sprintf("http-get %s :%v%s", strings.ToLower(p.HTTPGet.Scheme), p.HTTPGet.Port, p.HTTPGet.Path)
+	case p.TCPSocket != nil:
+		action = fmt.Sprintf("tcp :%v", p.TCPSocket.Port)
+	case p.Exec != nil:
+		action = "exec " + strings.Join(p.Exec.Command, " ")
+	case p.GRPC != nil:
+		action = fmt.Sprintf("grpc :%d %s", p.GRPC.Port, p.GRPC.Service)
+	}
+	return fmt.Sprintf("%s delay=%ds timeout=%ds period=%ds failure=%d", action, p.InitialDelaySeconds, p.TimeoutSeconds, p.PeriodSeconds, p.FailureThreshold)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// openPodDetail is the `d` action on the selected pod
+func (m model) openPodDetail() (tea.Model, tea.Cmd) {
+	if m.selectedPodIndex >= len(m.podNames) {
+		return m, nil
+	}
+	if m.currentKubeContext == "" || m.currentNamespace == "" {
+		m.statusMessage = "Kube context not known yet"
+		return m, nil
+	}
+	m.podDetailName = m.podNames[m.selectedPodIndex]
+	m.podDetailContext, m.podDetailNamespace = m.currentKubeContext, m.currentNamespace
+	m.showPodDetail = true
+	m.setPodDetail("Loading...")
+	return m, getPodDetailCmd(m.podDetailName, m.podDetailContext, m.podDetailNamespace)
+}
+
+func (m *model) setPodDetail(content string) {
+	header := lipgloss.Height(logViewerHeaderStyle.Render(" "))
+	footer := lipgloss.Height(logViewerFooterStyle.Render(" "))
+	if m.podDetailViewport.Width != m.width || m.podDetailViewport.Height != m.height-header-footer {
+		m.podDetailViewport = viewport.New(m.width, m.height-header-footer)
+		m.podDetailViewport.YPosition = header
+	}
+	m.podDetailContent = content
+	m.podDetailViewport.SetContent(content)
+}
+
+func (m model) podDetailView() string {
+	header := logViewerHeaderStyle.Render(fmt.Sprintf("Pod %s · %s/%s", m.podDetailName, m.podDetailContext, m.podDetailNamespace))
+	footer := logViewerFooterStyle.Render("r: Refresh | Scroll with arrow keys / mouse wheel | Esc: Back")
+	return lipgloss.JoinVertical(lipgloss.Left, header, m.podDetailViewport.View(), footer)
+}
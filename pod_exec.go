@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Shells tried in order; distroless images usually have none of them
+var execShells = []string{"/bin/bash", "/bin/ash", "/bin/sh", "/busybox/sh"}
+
+type execContainersMsg struct {
+	target     podActionTarget
+	containers []string
+	err        error
+}
+
+type execShellMsg struct {
+	target podActionTarget
+	shell  string
+	err    error
+}
+
+type execDoneMsg struct {
+	target podActionTarget
+	args   []string
+	err    error
+}
+
+// listContainersCmd fetches the container names of pod, in spec order
+func listContainersCmd(pod, kubeContext, namespace string) tea.Cmd {
+	return func() tea.Msg {
+		t := podActionTarget{Action: "exec", Pod: pod, Context: kubeContext, Namespace: namespace}
+		names, err := kubectlJSONPath(kubeContext, namespace, "pod/"+pod, "{.spec.containers[*].name}")
+		if err != nil {
+			return execContainersMsg{err: err}
+		}
+		containers := strings.Fields(names)
+		if len(containers) == 0 {
+			return execContainersMsg{err: fmt.Errorf("%s has no containers", pod)}
+		}
+		return execContainersMsg{target: t, containers: containers}
+	}
+}
+
+// findShellCmd runs `<shell> -c true` in the container for each candidate and
+// picks the first that works
+func findShellCmd(t podActionTarget) tea.Cmd {
+	return func() tea.Msg {
+		for _, shell := range execShells {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			cmd := kubectlInCtx(ctx, t.Context, t.Namespace, "exec", t.Pod, "-c", t.Container, "--", shell, "-c", "true")
+			if err := cmd.Start(); err != nil {
+				cancel()
+				return execShellMsg{target: t, err: err}
+			}
+			err := cmd.Wait()
+			cancel()
+			if err == nil {
+				return execShellMsg{target: t, shell: shell}
+			}
+		}
+		return execShellMsg{target: t, err: fmt.Errorf("no shell found in container %s (tried %s)", t.Container, strings.Join(execShells, ", "))}
+	}
+}
+
+// execShell suspends the TUI and hands the terminal to an interactive shell;
+// execDoneMsg arrives when it exits
+func execShell(t podActionTarget, shell string) tea.Cmd {
+	args := []string{"exec", "-it", t.Pod, "-c", t.Container, "--", shell}
+	return tea.ExecProcess(kubectlIn(t.Context, t.Namespace, args...), func(err error) tea.Msg {
+		return execDoneMsg{target: t, args: args, err: err}
+	})
+}
+
+// startExec is the `e` action on the selected pod
+func (m model) startExec() (tea.Model, tea.Cmd) {
+	if m.selectedPodIndex >= len(m.podNames) {
+		return m, nil
+	}
+	if m.cfg.readOnly(m.currentKubeContext) {
+		m.statusMessage = "Read-only mode: shells are disabled for " + m.currentKubeContext
+		return m, nil
+	}
+	if m.currentKubeContext == "" || m.currentNamespace == "" {
+		m.statusMessage = "Kube context not known yet"
+		return m, nil
+	}
+	pod := m.podNames[m.selectedPodIndex]
+	m.statusMessage = "Looking up containers of " + pod + "..."
+	return m, listContainersCmd(pod, m.currentKubeContext, m.currentNamespace)
+}
+
+func (m model) chooseExecContainer(t podActionTarget, container string) (tea.Model, tea.Cmd) {
+	t.Container = container
+	m.execTarget = nil
+	m.statusMessage = "Looking for a shell in " + container + "..."
+	return m, findShellCmd(t)
+}
+
+// Picker shown when the pod has more than one container
+func (m model) execPickerView() string {
+	t := m.execTarget
+	lines := []string{
+		headerStyle.Render("Shell into " + t.Pod),
+		"",
+		fmt.Sprintf("Context:   %s", pendingStyle.Render(t.Context)),
+		fmt.Sprintf("Namespace: %s", pendingStyle.Render(t.Namespace)),
+		"",
+	}
+	for i, c := range m.execContainers {
+		if i == m.execContainerIndex {
+			lines = append(lines, highlightStyle.Render("> "+c))
+		} else {
+			lines = append(lines, "  "+c)
+		}
+	}
+	lines = append(lines, "", "↑/↓: Choose Container | Enter: Open Shell | Esc: Cancel")
+	return m.modalView(lines)
+}
+
+// exitStatus describes how the shell ended; a non-zero status usually is just
+// that of the last command typed
+func exitStatus(err error) string {
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		return fmt.Sprintf("exited with status %d", exitErr.ExitCode())
+	}
+	return "failed: " + err.Error()
+}
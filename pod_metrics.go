@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Samples kept per pod for the trend arrows, one per refresh
+const maxPodUsageSamples = 8
+
+// podUsage sums the containers of a pod. A limit is 0 unless every container
+// has one, as the pod can otherwise use more than their sum.
+type podUsage struct {
+	CPU, Memory           float64 // millicores and bytes
+	CPULimit, MemoryLimit float64
+}
+
+type podMetricsMsg struct {
+	kubeContext string // as read in
+	namespace   string
+	usage       map[string]podUsage
+	err         error
+}
+
+// getPodMetricsCmd reads pod usage in the selected context and namespace from
+// the metrics.k8s.io API, falling back to `kubectl top pods`. The limits come
+// from the pods pane's pod list.
+func getPodMetricsCmd(kubeContext, namespace string) tea.Cmd {
+	if kubeContext == "" || namespace == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		msg := podMetricsMsg{kubeContext: kubeContext, namespace: namespace}
+		msg.usage, msg.err = metricsAPIUsage(kubeContext, namespace)
+		if msg.err != nil {
+			msg.usage, msg.err = kubectlTopUsage(kubeContext, namespace)
+		}
+		if msg.err != nil {
+			msg.err = fmt.Errorf("failed to get pod metrics: %w", msg.err)
+		}
+		return msg
+	}
+}
+
+func metricsAPIUsage(kubeContext, namespace string) (map[string]podUsage, error) {
+	out, err := kubectlOutput(kubeContext, namespace, "get", "--raw", "/apis/metrics.k8s.io/v1beta1/namespaces/"+namespace+"/pods")
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Containers []struct {
+				Usage map[string]string `json:"usage"`
+			} `json:"containers"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, err
+	}
+	usage := map[string]podUsage{}
+	for _, item := range list.Items {
+		var u podUsage
+		for _, c := range item.Containers {
+			cpu, _ := parseQuantity(c.Usage["cpu"])
+			memory, _ := parseQuantity(c.Usage["memory"])
+			u.CPU += cpu * 1000
+			u.Memory += memory
+		}
+		usage[item.Metadata.Name] = u
+	}
+	return usage, nil
+}
+
+// kubectlTopUsage parses `kubectl top pods`: NAME CPU(cores) MEMORY(bytes)
+func kubectlTopUsage(kubeContext, namespace string) (map[string]podUsage, error) {
+	out, err := kubectlIn(kubeContext, namespace, "top", "pods", "--no-headers").Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			err = fmt.Errorf("%s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	usage := map[string]podUsage{}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		cpu, err := parseQuantity(fields[1])
+		if err != nil {
+			continue
+		}
+		memory, err := parseQuantity(fields[2])
+		if err != nil {
+			continue
+		}
+		usage[fields[0]] = podUsage{CPU: cpu * 1000, Memory: memory}
+	}
+	return usage, nil
+}
+
+func podLimits(pod kubePod) (cpu, memory float64) {
+	cpuLimited, memoryLimited := true, true
+	for _, c := range pod.Spec.Containers {
+		if v, err := parseQuantity(c.Resources.Limits["cpu"]); err == nil {
+			cpu += v * 1000
+		} else {
+			cpuLimited = false
+		}
+		if v, err := parseQuantity(c.Resources.Limits["memory"]); err == nil {
+			memory += v
+		} else {
+			memoryLimited = false
+		}
+	}
+	if !cpuLimited {
+		cpu = 0
+	}
+	if !memoryLimited {
+		memory = 0
+	}
+	return cpu, memory
+}
+
+var quantitySuffixes = map[string]float64{
+	"Ki": 1 << 10, "Mi": 1 << 20, "Gi": 1 << 30, "Ti": 1 << 40, "Pi": 1 << 50, "Ei": 1 << 60,
+	"n": 1e-9, "u": 1e-6, "m": 1e-3, "k": 1e3, "M": 1e6, "G": 1e9, "T": 1e12, "P": 1e15, "E": 1e18,
+}
+
+// parseQuantity reads a Kubernetes resource quantity such as "250m", "1.5",
+// "128Mi" or "1e9" in base units (cores, bytes)
+func parseQuantity(s string) (float64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty quantity")
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, nil
+	}
+	for _, n := range []int{2, 1} {
+		if len(s) <= n {
+			continue
+		}
+		if factor, ok := quantitySuffixes[s[len(s)-n:]]; ok {
+			v, err := strconv.ParseFloat(s[:len(s)-n], 64)
+			if err != nil {
+				return 0, err
+			}
+			return v * factor, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid quantity %q", s)
+}
+
+// percentOf is usage as a percentage of limit, or -1 without a limit
+func percentOf(usage, limit float64) float64 {
+	if limit <= 0 {
+		return -1
+	}
+	return usage / limit * 100
+}
+
+// highUsage reports whether a pod is at or above either threshold
+func (u podUsage) highUsage(cpuPercent, memoryPercent int) bool {
+	return percentOf(u.CPU, u.CPULimit) >= float64(cpuPercent) || percentOf(u.Memory, u.MemoryLimit) >= float64(memoryPercent)
+}
+
+// trendArrow compares the latest sample with the average of the earlier
+// ones; changes within 10% read as flat
+func trendArrow(values []float64) string {
+	if len(values) < 2 {
+		return " "
+	}
+	var sum float64
+	for _, v := range values[:len(values)-1] {
+		sum += v
+	}
+	avg := sum / float64(len(values)-1)
+	latest := values[len(values)-1]
+	switch {
+	case latest > avg*1.1 && latest-avg >= 1:
+		return "↑"
+	case latest < avg*0.9 && avg-latest >= 1:
+		return "↓"
+	}
+	return "→"
+}
+
+// formatMillicores writes CPU like `kubectl top`, always in millicores
+func formatMillicores(m float64) string {
+	return fmt.Sprintf("%dm", int(math.Round(m)))
+}
+
+func formatBytes(b float64) string {
+	switch {
+	case b >= 1<<30:
+		return strconv.FormatFloat(b/(1<<30), 'f', 1, 64) + "Gi"
+	case b >= 1<<20:
+		return fmt.Sprintf("%dMi", int(math.Round(b/(1<<20))))
+	}
+	return fmt.Sprintf("%dKi", int(math.Round(b/(1<<10))))
+}
+
+func formatPercent(p float64) string {
+	if p < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d%%", int(math.Round(p)))
+}
+
+// withUsageColumns appends CPU and memory usage, percent of limit and trend
+// to the plain `kubectl get pods` lines; line i is pod podNames[i] as for the
+// selection. Pods at or above the thresholds are marked with "!", which
+// colorizeKubectlPodsWithSelection shows in red.
+func withUsageColumns(output string, podNames []string, samples map[string][]podUsage, high map[string]bool) string {
+	lines := strings.Split(output, "\n")
+	if len(lines) == 0 || len(samples) == 0 {
+		return output
+	}
+	format := "  %-6s %5s%s %-6s %5s%s"
+	lines[0] += fmt.Sprintf(format, "CPU", "%LIM", " ", "MEM", "%LIM", " ")
+	for i := 1; i < len(lines) && i-1 < len(podNames); i++ {
+		history := samples[podNames[i-1]]
+		if len(history) == 0 {
+			continue
+		}
+		u := history[len(history)-1]
+		cpu := make([]float64, len(history))
+		memory := make([]float64, len(history))
+		for j, s := range history {
+			cpu[j], memory[j] = s.CPU, s.Memory
+		}
+		columns := fmt.Sprintf(format,
+			formatMillicores(u.CPU), formatPercent(percentOf(u.CPU, u.CPULimit)), trendArrow(cpu),
+			formatBytes(u.Memory), formatPercent(percentOf(u.Memory, u.MemoryLimit)), trendArrow(memory))
+		if high[podNames[i-1]] {
+			columns += " !"
+		}
+		lines[i] += columns
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *model) setPodMetrics(msg podMetricsMsg) {
+	if msg.kubeContext != m.currentKubeContext || msg.namespace != m.currentNamespace {
+		// read before a context or namespace switch
+		return
+	}
+	m.podMetricsErr = ""
+	if msg.err != nil {
+		m.podMetricsErr = msg.err.Error()
+		return
+	}
+	if m.podUsageSamples == nil {
+		m.podUsageSamples = map[string][]podUsage{}
+	}
+	for pod := range m.podUsageSamples {
+		if _, ok := msg.usage[pod]; !ok {
+			delete(m.podUsageSamples, pod)
+		}
+	}
+	m.podHighUsage = map[string]bool{}
+	for pod, u := range msg.usage {
+		if limits, ok := m.podResourceLimits[pod]; ok {
+			u.CPULimit, u.MemoryLimit = limits.CPULimit, limits.MemoryLimit
+		}
+		samples := append(m.podUsageSamples[pod], u)
+		if len(samples) > maxPodUsageSamples {
+			samples = samples[len(samples)-maxPodUsageSamples:]
+		}
+		m.podUsageSamples[pod] = samples
+		if u.highUsage(m.cfg.HighCPUPercent, m.cfg.HighMemoryPercent) {
+			m.podHighUsage[pod] = true
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{in: "2", want: 2},
+		{in: "1.5", want: 1.5},
+		{in: "1e9", want: 1e9},
+		{in: "250m", want: 0.25},
+		{in: "1500m", want: 1.5},
+		{in: "120000n", want: 0.00012},
+		{in: "512Ki", want: 512 * 1024},
+		{in: "128Mi", want: 128 * 1024 * 1024},
+		{in: "1.5Gi", want: 1.5 * 1024 * 1024 * 1024},
+		{in: "2G", want: 2e9},
+		{in: "100k", want: 1e5},
+		{in: "1E", want: 1e18},
+		{in: "", wantErr: true},
+		{in: "m", wantErr: true},
+		{in: "Mi", wantErr: true},
+		{in: "12Xi", wantErr: true},
+		{in: "lotsMi", wantErr: true},
+		{in: "1.2.3m", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseQuantity(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !near(got, tt.want) {
+				t.Errorf("got %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
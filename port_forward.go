@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// portForward is one `kubectl port-forward` running in the background. Its
+// process reports through portForwardMsg; only Update changes the fields.
+type portForward struct {
+	ID         int
+	Target     string // "pod/web-1" or "service/web"
+	Context    string
+	Namespace  string
+	LocalPort  int // 0 until kubectl has picked one
+	RemotePort int
+	Status     string // "starting", "active", "stopped" or "failed"
+	Err        string // last error reported by kubectl
+	Started    time.Time
+	cmd        *exec.Cmd
+	stopping   bool
+}
+
+// forwardOption is a port offered when starting a forward
+type forwardOption struct {
+	Target    string
+	Port      int
+	Label     string
+	Context   string
+	Namespace string
+}
+
+type forwardOptionsMsg struct {
+	pod     string
+	options []forwardOption
+	err     error
+}
+
+// portForwardMsg carries an update from a forward's process: the local port
+// once listening, an error line, or its exit
+type portForwardMsg struct {
+	id        int
+	localPort int
+	err       string
+	exited    bool
+}
+
+var forwardingFromRE = regexp.MustCompile(`^Forwarding from [^ ]+:(\d+) -> \d+`)
+
+// forwardOptionsCmd lists the TCP ports declared by the pod's containers and
+// by the services whose selector matches the pod
+func forwardOptionsCmd(pod, kubeContext, namespace string) tea.Cmd {
+	return func() tea.Msg {
+		var p struct {
+			Metadata struct {
+				Labels map[string]string `json:"labels"`
+			} `json:"metadata"`
+			Spec struct {
+				Containers []struct {
+					Name  string `json:"name"`
+					Ports []struct {
+						Name          string `json:"name"`
+						ContainerPort int    `json:"containerPort"`
+						Protocol      string `json:"protocol"`
+					} `json:"ports"`
+				} `json:"containers"`
+			} `json:"spec"`
+		}
+		out, err := kubectlOutput(kubeContext, namespace, "get", "pod", pod, "-o", "json")
+		if err != nil {
+			return forwardOptionsMsg{err: err}
+		}
+		if err := json.Unmarshal(out, &p); err != nil {
+			return forwardOptionsMsg{err: err}
+		}
+		var options []forwardOption
+		for _, c := range p.Spec.Containers {
+			for _, port := range c.Ports {
+				if port.Protocol != "" && port.Protocol != "TCP" {
+					continue
+				}
+				label := fmt.Sprintf("pod/%s :%d (%s", pod, port.ContainerPort, c.Name)
+				if port.Name != "" {
+					label += " " + port.Name
+				}
+				options = append(options, forwardOption{Target: "pod/" + pod, Port: port.ContainerPort, Label: label + ")", Context: kubeContext, Namespace: namespace})
+			}
+		}
+
+		var services struct {
+			Items []struct {
+				Metadata struct {
+					Name string `json:"name"`
+				} `json:"metadata"`
+				Spec struct {
+					Selector map[string]string `json:"selector"`
+					Ports    []struct {
+						Name     string `json:"name"`
+						Port     int    `json:"port"`
+						Protocol string `json:"protocol"`
+					} `json:"ports"`
+				} `json:"spec"`
+			} `json:"items"`
+		}
+		// services are a bonus; a missing RBAC permission only hides them
+		if out, err := kubectlOutput(kubeContext, namespace, "get", "services", "-o", "json"); err == nil && json.Unmarshal(out, &services) == nil {
+			for _, svc := range services.Items {
+				if !selectorMatches(svc.Spec.Selector, p.Metadata.Labels) {
+					continue
+				}
+				for _, port := range svc.Spec.Ports {
+					if port.Protocol != "" && port.Protocol != "TCP" {
+						continue
+					}
+					label := fmt.Sprintf("service/%s :%d", svc.Metadata.Name, port.Port)
+					if port.Name != "" {
+						label += " (" + port.Name + ")"
+					}
+					options = append(options, forwardOption{Target: "service/" + svc.Metadata.Name, Port: port.Port, Label: label, Context: kubeContext, Namespace: namespace})
+				}
+			}
+		}
+		if len(options) == 0 {
+			return forwardOptionsMsg{err: fmt.Errorf("%s declares no TCP ports and no service selects it", pod)}
+		}
+		return forwardOptionsMsg{pod: pod, options: options}
+	}
+}
+
+// A service without a selector has its endpoints managed by hand and never
+// matches
+func selectorMatches(selector, labels map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// startPortForward launches kubectl; localPort 0 lets kubectl choose a free
+// port. The process's output is relayed to ch until it exits or done is
+// closed, so nothing blocks on ch once Update no longer reads it.
+func startPortForward(ch chan<- portForwardMsg, done <-chan struct{}, f *portForward) error {
+	local := ""
+	if f.LocalPort > 0 {
+		local = strconv.Itoa(f.LocalPort)
+	}
+	cmd := kubectlIn(f.Context, f.Namespace, "port-forward", f.Target, fmt.Sprintf("%s:%d", local, f.RemotePort))
+	id := f.ID
+	send := func(msg portForwardMsg) {
+		select {
+		case ch <- msg:
+		case <-done:
+		}
+	}
+	stdout := &lineWriter{line: func(line string) {
+		if match := forwardingFromRE.FindStringSubmatch(line); match != nil {
+			port, _ := strconv.Atoi(match[1])
+			send(portForwardMsg{id: id, localPort: port})
+		}
+	}}
+	var lastErr string
+	stderr := &lineWriter{line: func(line string) {
+		if line = strings.TrimSpace(line); line != "" {
+			lastErr = line
+			send(portForwardMsg{id: id, err: line})
+		}
+	}}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	// don't hang on output still held open by a child after kubectl exits
+	cmd.WaitDelay = 2 * time.Second
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	f.cmd = cmd
+	go func() {
+		err := cmd.Wait()
+		msg := portForwardMsg{id: id, exited: true}
+		if err != nil && lastErr == "" {
+			msg.err = err.Error()
+		}
+		send(msg)
+	}()
+	return nil
+}
+
+// lineWriter calls line for every complete line written to it
+type lineWriter struct {
+	buf  []byte
+	line func(string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.line(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+}
+
+// waitForwardCmd delivers the next update from any forward; Update re-arms it
+func waitForwardCmd(ch <-chan portForwardMsg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+func (f *portForward) stop() {
+	if f.cmd != nil && f.cmd.Process != nil && (f.Status == "starting" || f.Status == "active") {
+		f.stopping = true
+		_ = f.cmd.Process.Kill()
+	}
+}
+
+// apply updates the forward from its process
+func (f *portForward) apply(msg portForwardMsg) {
+	switch {
+	case msg.exited && f.stopping:
+		f.Status = "stopped"
+	case msg.exited:
+		f.Status = "failed"
+		if msg.err != "" {
+			f.Err = msg.err
+		}
+	case msg.localPort > 0:
+		f.LocalPort = msg.localPort
+		f.Status = "active"
+	case msg.err != "":
+		f.Err = msg.err
+	}
+}
+
+func (m model) activeForwards() int {
+	n := 0
+	for _, f := range m.portForwards {
+		if f.Status == "starting" || f.Status == "active" {
+			n++
+		}
+	}
+	return n
+}
+
+// stopPortForwards tears down every forward; called when the program exits
+func (m model) stopPortForwards() {
+	close(m.forwardsDone)
+	for _, f := range m.portForwards {
+		f.stop()
+	}
+}
+
+// startForwardPicker is the `p` action on the selected pod
+func (m model) startForwardPicker() (tea.Model, tea.Cmd) {
+	if m.selectedPodIndex >= len(m.podNames) {
+		return m, nil
+	}
+	if m.currentKubeContext == "" || m.currentNamespace == "" {
+		m.statusMessage = "Kube context not known yet"
+		return m, nil
+	}
+	pod := m.podNames[m.selectedPodIndex]
+	m.statusMessage = "Looking up ports of " + pod + "..."
+	return m, forwardOptionsCmd(pod, m.currentKubeContext, m.currentNamespace)
+}
+
+func (m model) confirmForward() (tea.Model, tea.Cmd) {
+	opt := m.forwardOptions[m.forwardOptionIndex]
+	local := 0
+	if v := strings.TrimSpace(m.localPortInput.Value()); v != "" {
+		port, err := strconv.Atoi(v)
+		if err != nil || port < 1 || port > 65535 {
+			m.statusMessage = "Local port must be between 1 and 65535, or empty to pick one"
+			return m, nil
+		}
+		local = port
+	}
+	m.forwardOptions = nil
+	m.localPortInput.Blur()
+
+	m.nextForwardID++
+	f := &portForward{ID: m.nextForwardID, Target: opt.Target, Context: opt.Context, Namespace: opt.Namespace, LocalPort: local, RemotePort: opt.Port, Status: "starting", Started: time.Now()}
+	if err := startPortForward(m.forwardEvents, m.forwardsDone, f); err != nil {
+		m.statusMessage = "Failed to start port-forward: " + err.Error()
+		return m, nil
+	}
+	m.portForwards = append(m.portForwards, f)
+	m.statusMessage = fmt.Sprintf("Forwarding %s :%d...", f.Target, f.RemotePort)
+	return m, nil
+}
+
+// clearForwards drops stopped and failed forwards from the panel
+func (m *model) clearForwards() {
+	var kept []*portForward
+	for _, f := range m.portForwards {
+		if f.Status == "starting" || f.Status == "active" {
+			kept = append(kept, f)
+		}
+	}
+	m.portForwards = kept
+	if m.selectedForward >= len(kept) {
+		m.selectedForward = 0
+	}
+}
+
+func (m model) forwardPickerView() string {
+	opt := m.forwardOptions[0]
+	lines := []string{
+		headerStyle.Render("Port-forward " + m.forwardPod),
+		"",
+		fmt.Sprintf("Context:   %s", pendingStyle.Render(opt.Context)),
+		fmt.Sprintf("Namespace: %s", pendingStyle.Render(opt.Namespace)),
+		"",
+	}
+	for i, o := range m.forwardOptions {
+		if i == m.forwardOptionIndex {
+			lines = append(lines, highlightStyle.Render("> "+o.Label))
+		} else {
+			lines = append(lines, "  "+o.Label)
+		}
+	}
+	lines = append(lines, "", m.localPortInput.View(), "", "↑/↓: Choose Port | Enter: Start | Esc: Cancel")
+	return m.modalView(lines)
+}
+
+func (m model) forwardsView() string {
+	header := logViewerHeaderStyle.Render(fmt.Sprintf("Port-forwards · %d active", m.activeForwards()))
+	footer := logViewerFooterStyle.Render("↑/↓: Select | x: Stop | c: Clear Finished | Esc: Back")
+	var lines []string
+	for i, f := range m.portForwards {
+		local := "auto"
+		if f.LocalPort > 0 {
+			local = fmt.Sprintf("localhost:%d", f.LocalPort)
+		}
+		style := pendingStyle
+		switch f.Status {
+		case "active":
+			style = statusResolvedStyle
+		case "failed":
+			style = statusUnresolvedStyle
+		case "stopped":
+			style = defaultStyle
+		}
+		line := fmt.Sprintf("%-32s %-16s → %-6d %s  %s", f.Target, local, f.RemotePort, style.Render(fmt.Sprintf("%-8s", f.Status)), formatDuration(time.Since(f.Started)))
+		if f.Context != m.currentKubeContext || f.Namespace != m.currentNamespace {
+			line += "  " + levelInfoStyle.Render(f.Context+"/"+f.Namespace)
+		}
+		if i == m.selectedForward {
+			line = highlightStyle.Render(line)
+		}
+		lines = append(lines, line)
+		if f.Err != "" {
+			lines = append(lines, "    "+statusUnresolvedStyle.Render(f.Err))
+		}
+	}
+	if len(lines) == 0 {
+		lines = []string{logViewerFooterStyle.Render("No port-forwards. Press p on a pod to start one.")}
+	}
+	body := lipgloss.NewStyle().Height(m.height - lipgloss.Height(header) - lipgloss.Height(footer)).Render(strings.Join(lines, "\n"))
+	return lipgloss.JoinVertical(lipgloss.Left, header, body, footer)
+}
+
+func (m model) handlePortForwardMsg(msg portForwardMsg) (tea.Model, tea.Cmd) {
+	for _, f := range m.portForwards {
+		if f.ID != msg.id {
+			continue
+		}
+		wasActive := f.Status == "active"
+		f.apply(msg)
+		switch {
+		case f.Status == "failed":
+			m.statusMessage = fmt.Sprintf("Port-forward %s :%d failed: %s", f.Target, f.RemotePort, f.Err)
+		case f.Status == "active" && !wasActive:
+			m.statusMessage = fmt.Sprintf("Forwarding localhost:%d → %s :%d", f.LocalPort, f.Target, f.RemotePort)
+		}
+	}
+	return m, waitForwardCmd(m.forwardEvents)
+}
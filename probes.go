@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Description of what a check probes, shown by the link actions
+func (c healthCheckConfig) target() string {
+	switch c.Type {
+	case "tcp", "postgres", "redis":
+		return c.Type + "://" + c.Address
+	case "dns":
+		return "dns:" + c.Address + "/" + c.recordType()
+	case "grpc":
+		return "grpc://" + c.Address + "/" + c.Service
+	default:
+		return c.URL
+	}
+}
+
+func (c healthCheckConfig) recordType() string {
+	if c.RecordType == "" {
+		return "A"
+	}
+	return strings.ToUpper(c.RecordType)
+}
+
+// runNetworkProbe handles every check type except HTTP. It returns a short
+// detail line and the failed expectations; err means the probe itself failed.
+func runNetworkProbe(ctx context.Context, check healthCheckConfig, auth *probeAuth) (detail string, failures []string, err error) {
+	switch check.Type {
+	case "tcp":
+		conn, err := dialProbe(ctx, check.Address)
+		if err != nil {
+			return "", nil, err
+		}
+		conn.Close()
+		return "connected", nil, nil
+	case "dns":
+		return probeDNS(ctx, check)
+	case "grpc":
+		return probeGRPCHealth(ctx, check, auth)
+	case "postgres":
+		return probePostgres(ctx, check.Address)
+	case "redis":
+		return probeRedis(ctx, check.Address, auth)
+	default:
+		return "", nil, fmt.Errorf("unknown check type %q", check.Type)
+	}
+}
+
+func dialProbe(ctx context.Context, address string) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	return conn, nil
+}
+
+// dnsResolver is replaced in tests
+var dnsResolver = net.DefaultResolver
+
+func probeDNS(ctx context.Context, check healthCheckConfig) (string, []string, error) {
+	r := dnsResolver
+	var records []string
+	switch check.recordType() {
+	case "A", "AAAA":
+		addrs, err := r.LookupIPAddr(ctx, check.Address)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, a := range addrs {
+			if (a.IP.To4() != nil) == (check.recordType() == "A") {
+				records = append(records, a.IP.String())
+			}
+		}
+	case "CNAME":
+		cname, err := r.LookupCNAME(ctx, check.Address)
+		if err != nil {
+			return "", nil, err
+		}
+		records = []string{strings.TrimSuffix(cname, ".")}
+	case "TXT":
+		txt, err := r.LookupTXT(ctx, check.Address)
+		if err != nil {
+			return "", nil, err
+		}
+		records = txt
+	case "MX":
+		mx, err := r.LookupMX(ctx, check.Address)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, m := range mx {
+			records = append(records, strings.TrimSuffix(m.Host, "."))
+		}
+	default:
+		return "", nil, fmt.Errorf("unsupported record type %q", check.RecordType)
+	}
+
+	var failures []string
+	if len(records) == 0 {
+		failures = append(failures, "no "+check.recordType()+" records")
+	}
+	for _, want := range check.Expect {
+		if !slices.ContainsFunc(records, func(got string) bool { return strings.EqualFold(got, strings.TrimSuffix(want, ".")) }) {
+			failures = append(failures, fmt.Sprintf("expected %s record %s", check.recordType(), want))
+		}
+	}
+	return check.recordType() + " " + strings.Join(records, ", "), failures, nil
+}
+
+var grpcServingStatus = map[uint64]string{0: "UNKNOWN", 1: "SERVING", 2: "NOT_SERVING", 3: "SERVICE_UNKNOWN"}
+
+// probeGRPCHealth calls grpc.health.v1.Health/Check over HTTP/2 (h2c unless
+// tls is set). The messages only have a single field each, so they are
+// encoded by hand instead of pulling in the gRPC and protobuf libraries.
+// Headers and credentials of the check are sent as metadata.
+func probeGRPCHealth(ctx context.Context, check healthCheckConfig, auth *probeAuth) (string, []string, error) {
+	// HealthCheckRequest{service = 1}
+	msg := append([]byte{0x0a}, binary.AppendUvarint(nil, uint64(len(check.Service)))...)
+	msg = append(msg, check.Service...)
+	frame := append([]byte{0}, binary.BigEndian.AppendUint32(nil, uint32(len(msg)))...)
+	frame = append(frame, msg...)
+
+	scheme := "http"
+	transport := &http.Transport{Protocols: new(http.Protocols)}
+	if check.TLS {
+		scheme = "https"
+		host, _, _ := net.SplitHostPort(check.Address)
+		transport.TLSClientConfig = probeTLSConfig(&tlsInfo{}, host, auth)
+		transport.Protocols.SetHTTP2(true)
+	} else {
+		transport.Protocols.SetUnencryptedHTTP2(true)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, scheme+"://"+check.Address+"/grpc.health.v1.Health/Check", bytes.NewReader(frame))
+	if err != nil {
+		return "", nil, err
+	}
+	auth.apply(req)
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+	// grpc-status arrives in the trailers, or in the headers for errors
+	grpcStatus := resp.Trailer.Get("Grpc-Status")
+	grpcMessage := resp.Trailer.Get("Grpc-Message")
+	if grpcStatus == "" {
+		grpcStatus = resp.Header.Get("Grpc-Status")
+		grpcMessage = resp.Header.Get("Grpc-Message")
+	}
+	if grpcStatus == "" {
+		// not a gRPC server, e.g. a proxy answering 404
+		return "", []string{"no grpc-status in response, HTTP " + resp.Status}, nil
+	}
+	if grpcStatus != "0" {
+		return "", []string{fmt.Sprintf("grpc-status %s %s", grpcStatus, grpcMessage)}, nil
+	}
+	if len(body) < 5 {
+		return "", nil, errors.New("empty gRPC response")
+	}
+	// HealthCheckResponse{status = 1}; an empty message means UNKNOWN (0)
+	var status uint64
+	payload := body[5:]
+	if len(payload) >= 2 && payload[0] == 0x08 {
+		status, _ = binary.Uvarint(payload[1:])
+	}
+	name := grpcServingStatus[status]
+	if status != 1 {
+		return name, []string{"service status " + name + ", expected SERVING"}, nil
+	}
+	return name, nil, nil
+}
+
+// probePostgres sends an SSLRequest, which every PostgreSQL server answers
+// with a single 'S' or 'N' byte before any authentication.
+func probePostgres(ctx context.Context, address string) (string, []string, error) {
+	conn, err := dialProbe(ctx, address)
+	if err != nil {
+		return "", nil, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}); err != nil {
+		return "", nil, err
+	}
+	reply := make([]byte, 1)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return "", nil, err
+	}
+	switch reply[0] {
+	case 'S':
+		return "accepting connections (SSL)", nil, nil
+	case 'N':
+		return "accepting connections", nil, nil
+	default:
+		return "", []string{fmt.Sprintf("unexpected reply %q to SSLRequest", reply[0])}, nil
+	}
+}
+
+// probeRedis sends PING, preceded by AUTH when the check has basicAuth; the
+// username may be empty for servers without ACLs
+func probeRedis(ctx context.Context, address string, auth *probeAuth) (string, []string, error) {
+	conn, err := dialProbe(ctx, address)
+	if err != nil {
+		return "", nil, err
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	command := func(args ...string) (string, error) {
+		if _, err := conn.Write(redisCommand(args...)); err != nil {
+			return "", err
+		}
+		line, err := reader.ReadString('\n')
+		return strings.TrimSpace(line), err
+	}
+	if auth != nil && auth.password != "" {
+		args := []string{"AUTH", auth.password}
+		if auth.username != "" {
+			args = []string{"AUTH", auth.username, auth.password}
+		}
+		line, err := command(args...)
+		if err != nil {
+			return "", nil, err
+		}
+		if line != "+OK" {
+			return "", []string{"AUTH answered with " + line}, nil
+		}
+	}
+	line, err := command("PING")
+	if err != nil {
+		return "", nil, err
+	}
+	if line != "+PONG" {
+		return "", []string{"PING answered with " + line}, nil
+	}
+	return "PONG", nil, nil
+}
+
+// redisCommand encodes a command as a RESP array of bulk strings
+func redisCommand(args ...string) []byte {
+	b := fmt.Appendf(nil, "*%d\r\n", len(args))
+	for _, arg := range args {
+		b = fmt.Appendf(b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return b
+}
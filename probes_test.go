@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// grpcHealthServer answers grpc.health.v1.Health/Check over h2c with status
+func grpcHealthServer(t *testing.T, status uint64) string {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/grpc.health.v1.Health/Check" || r.Header.Get("Content-Type") != "application/grpc" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.ReadAll(r.Body)
+		msg := append([]byte{0x08}, binary.AppendUvarint(nil, status)...)
+		if status == 0 {
+			msg = nil // proto3 omits the default value
+		}
+		frame := append([]byte{0}, binary.BigEndian.AppendUint32(nil, uint32(len(msg)))...)
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		_, _ = w.Write(append(frame, msg...))
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv.Listener.Addr().String()
+}
+
+func TestProbeGRPCHealth(t *testing.T) {
+	tests := []struct {
+		status  uint64
+		detail  string
+		failing bool
+	}{
+		{1, "SERVING", false},
+		{2, "NOT_SERVING", true},
+		{0, "UNKNOWN", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.detail, func(t *testing.T) {
+			addr := grpcHealthServer(t, tt.status)
+			detail, failures, err := probeGRPCHealth(testContext(t), healthCheckConfig{Type: "grpc", Address: addr}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if detail != tt.detail || (len(failures) > 0) != tt.failing {
+				t.Errorf("got %q %v, want %q failing=%v", detail, failures, tt.detail, tt.failing)
+			}
+		})
+	}
+}
+
+func TestProbeGRPCHealthWithoutGRPCStatus(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+	_, failures, err := probeGRPCHealth(testContext(t), healthCheckConfig{Type: "grpc", Address: srv.Listener.Addr().String()}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 1 || !strings.Contains(failures[0], "HTTP 404") {
+		t.Errorf("got %v, want the HTTP status reported", failures)
+	}
+}
+
+// stubListener accepts one connection, reads request bytes and writes reply
+func stubListener(t *testing.T, request int, reply string) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, err := io.ReadFull(conn, make([]byte, request)); err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte(reply))
+	}()
+	return l.Addr().String()
+}
+
+func TestProbePostgres(t *testing.T) {
+	tests := []struct {
+		reply, detail string
+	}{
+		{"S", "accepting connections (SSL)"},
+		{"N", "accepting connections"},
+	}
+	for _, tt := range tests {
+		addr := stubListener(t, 8, tt.reply)
+		detail, failures, err := probePostgres(testContext(t), addr)
+		if err != nil || len(failures) > 0 || detail != tt.detail {
+			t.Errorf("reply %q: got %q %v %v, want %q", tt.reply, detail, failures, err, tt.detail)
+		}
+	}
+}
+
+// redisStandIn serves PING and AUTH like a Redis server that requires
+// password (and user, when set) unless password is empty
+func redisStandIn(t *testing.T, user, password string) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		authed := password == ""
+		for {
+			// *<n> followed by n pairs of $<len> and the argument
+			header, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+			args := make([]string, n)
+			for i := range args {
+				if _, err := reader.ReadString('\n'); err != nil {
+					return
+				}
+				arg, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				args[i] = strings.TrimSuffix(arg, "\r\n")
+			}
+			reply := "-ERR unknown command\r\n"
+			switch {
+			case args[0] == "AUTH" && (slices.Equal(args[1:], []string{password}) && user == "" || slices.Equal(args[1:], []string{user, password})):
+				authed, reply = true, "+OK\r\n"
+			case args[0] == "AUTH":
+				reply = "-WRONGPASS invalid username-password pair or user is disabled.\r\n"
+			case args[0] == "PING" && authed:
+				reply = "+PONG\r\n"
+			case args[0] == "PING":
+				reply = "-NOAUTH Authentication required.\r\n"
+			}
+			if _, err := conn.Write([]byte(reply)); err != nil {
+				return
+			}
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestProbeRedis(t *testing.T) {
+	t.Setenv("ONCALL_TEST_REDIS_PASSWORD", "s3cret-pass")
+	tests := []struct {
+		name           string
+		user, password string // of the server
+		basicAuth      *basicAuthConfig
+		failure        string // empty when PONG is expected
+	}{
+		{"no password", "", "", nil, ""},
+		{"password required", "", "s3cret-pass", nil, "NOAUTH"},
+		{"password", "", "s3cret-pass", &basicAuthConfig{Password: "env:ONCALL_TEST_REDIS_PASSWORD"}, ""},
+		{"ACL user", "oncall", "s3cret-pass", &basicAuthConfig{Username: "oncall", Password: "env:ONCALL_TEST_REDIS_PASSWORD"}, ""},
+		{"wrong user", "admin", "s3cret-pass", &basicAuthConfig{Username: "oncall", Password: "env:ONCALL_TEST_REDIS_PASSWORD"}, "WRONGPASS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := healthCheckConfig{Name: tt.name, Type: "redis", Address: redisStandIn(t, tt.user, tt.password), BasicAuth: tt.basicAuth, TimeoutMS: 5000}
+			result := runHealthCheck(testContext(t), check)
+			failures := strings.Join(result.Failures, "\n")
+			if tt.failure == "" && (result.Status != "OK" || result.Detail != "PONG") {
+				t.Fatalf("got %s %q %s %s, want OK PONG", result.Status, result.Detail, failures, result.Err)
+			}
+			if tt.failure != "" && (result.Status != "FAIL" || !strings.Contains(failures, tt.failure)) {
+				t.Fatalf("got %s %s %s, want FAIL with %s", result.Status, failures, result.Err, tt.failure)
+			}
+			if strings.Contains(failures+result.Err, "s3cret-pass") {
+				t.Errorf("password shown: %s %s", failures, result.Err)
+			}
+		})
+	}
+}
+
+func TestProbeTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	addr := l.Addr().String()
+	detail, failures, err := runNetworkProbe(testContext(t), healthCheckConfig{Type: "tcp", Address: addr}, nil)
+	if err != nil || len(failures) > 0 || detail != "connected" {
+		t.Errorf("got %q %v %v, want connected", detail, failures, err)
+	}
+	l.Close()
+	if _, _, err := runNetworkProbe(testContext(t), healthCheckConfig{Type: "tcp", Address: addr}, nil); err == nil {
+		t.Error("connected to a closed port")
+	}
+}
+
+// DNS record types as in RFC 1035 and 3596
+const (
+	dnsTypeA     = 1
+	dnsTypeCNAME = 5
+	dnsTypeMX    = 15
+	dnsTypeTXT   = 16
+	dnsTypeAAAA  = 28
+)
+
+func dnsName(name string) []byte {
+	var b []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		b = append(append(b, byte(len(label))), label...)
+	}
+	return append(b, 0)
+}
+
+type dnsRecord struct {
+	Type uint16
+	Data []byte
+	Name string // the question's name when empty
+}
+
+// dnsStandIn returns a resolver whose queries go to a local UDP server that
+// answers every name with the records listed for the queried type
+func dnsStandIn(t *testing.T, records map[uint16][]dnsRecord) *net.Resolver {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			query := buf[:n]
+			// the question follows the 12 byte header: name, type, class
+			end := 12
+			for end < n && query[end] != 0 {
+				end += int(query[end]) + 1
+			}
+			end += 5
+			if end > n {
+				continue
+			}
+			qtype := binary.BigEndian.Uint16(query[end-4:])
+			answers := records[qtype]
+			resp := append([]byte{}, query[:2]...)                           // ID
+			resp = append(resp, 0x81, 0x80, 0, 1)                            // response, recursion; one question
+			resp = binary.BigEndian.AppendUint16(resp, uint16(len(answers))) // answers
+			resp = append(resp, 0, 0, 0, 0)                                  // no authority or additional records
+			resp = append(resp, query[12:end]...)
+			for _, rr := range answers {
+				if rr.Name != "" {
+					resp = append(resp, dnsName(rr.Name)...)
+				} else {
+					resp = append(resp, 0xc0, 12) // pointer to the question's name
+				}
+				resp = binary.BigEndian.AppendUint16(resp, rr.Type)
+				resp = append(resp, 0, 1, 0, 0, 0, 60) // class IN, TTL
+				resp = binary.BigEndian.AppendUint16(resp, uint16(len(rr.Data)))
+				resp = append(resp, rr.Data...)
+			}
+			_, _ = conn.WriteTo(resp, addr)
+		}
+	}()
+	return &net.Resolver{PreferGo: true, Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "udp", conn.LocalAddr().String())
+	}}
+}
+
+func TestProbeDNS(t *testing.T) {
+	saved := dnsResolver
+	standIn := dnsStandIn(t, map[uint16][]dnsRecord{
+		dnsTypeA:    {{dnsTypeA, []byte{10, 0, 0, 1}, ""}, {dnsTypeA, []byte{10, 0, 0, 2}, ""}},
+		dnsTypeAAAA: {{dnsTypeAAAA, net.ParseIP("fd00::1").To16(), ""}},
+		dnsTypeMX:   {{dnsTypeMX, append([]byte{0, 10}, dnsName("mx.example.test")...), ""}},
+		dnsTypeTXT:  {{dnsTypeTXT, append([]byte{11}, "v=spf1 -all"...), ""}},
+	})
+	// LookupCNAME asks for addresses and takes the name they are listed under
+	aliased := dnsStandIn(t, map[uint16][]dnsRecord{
+		dnsTypeA: {{dnsTypeCNAME, dnsName("lb.example.test"), ""}, {dnsTypeA, []byte{10, 0, 0, 3}, "lb.example.test"}},
+	})
+	t.Cleanup(func() { dnsResolver = saved })
+	tests := []struct {
+		resolver   *net.Resolver
+		recordType string
+		expect     []string
+		detail     string
+		failure    string
+	}{
+		{standIn, "", nil, "A 10.0.0.1, 10.0.0.2", ""},
+		{standIn, "A", []string{"10.0.0.2"}, "A 10.0.0.1, 10.0.0.2", ""},
+		{standIn, "A", []string{"10.0.0.9"}, "A 10.0.0.1, 10.0.0.2", "expected A record 10.0.0.9"},
+		{standIn, "AAAA", []string{"fd00::1"}, "AAAA fd00::1", ""},
+		{aliased, "CNAME", []string{"LB.example.test."}, "CNAME lb.example.test", ""},
+		{standIn, "MX", []string{"mx.example.test"}, "MX mx.example.test", ""},
+		{standIn, "TXT", []string{"v=spf1 -all"}, "TXT v=spf1 -all", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.recordType+strings.Join(tt.expect, ","), func(t *testing.T) {
+			dnsResolver = tt.resolver
+			check := healthCheckConfig{Type: "dns", Address: "api.example.test", RecordType: tt.recordType, Expect: tt.expect}
+			detail, failures, err := probeDNS(testContext(t), check)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if detail != tt.detail || strings.Join(failures, "\n") != tt.failure {
+				t.Errorf("got %q %v, want %q %q", detail, failures, tt.detail, tt.failure)
+			}
+		})
+	}
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
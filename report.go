@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Lines of log included per unhealthy pod
+const reportLogLines = 20
+
+// incidentReport is the data passed to the report template
+type incidentReport struct {
+	GeneratedAt time.Time
+	KubeContext string
+	Namespace   string
+	SentryQuery string
+	Issues      []sentryIssue
+	Endpoints   []reportEndpoint
+	Pods        []reportPod // unhealthy pods only
+	Timeline    []timelineEvent
+	Actions     []timelineEvent
+	Notes       []incidentNote // last 24h
+}
+
+type reportEndpoint struct {
+	Name     string
+	URL      string
+	Status   string
+	Latency  time.Duration
+	Err      string
+	Failures []string
+	SLOs     []sloStatus
+	// Over the last hour of probes
+	Samples      int
+	Availability float64 // percent
+	Min, Avg     time.Duration
+	P95, Max     time.Duration
+}
+
+type reportPod struct {
+	Name     string
+	Status   string
+	Restarts int
+	Logs     string
+}
+
+const defaultReportTemplate = `# Incident report {{ .GeneratedAt.Format "2006-01-02 15:04 MST" }}
+
+- Kube context: {{ or .KubeContext "unknown" }}{{ with .Namespace }} (namespace {{ . }}){{ end }}
+- Sentry query: ` + "`{{ .SentryQuery }}`" + `
+
+## Sentry issues
+
+{{ range .Issues -}}
+- [{{ .ShortID }}]({{ .Permalink }}) {{ .Title }} — {{ .Level }}, {{ .Count }} events, {{ .UserCount }} users, last seen {{ .LastSeen.Format "15:04" }}{{ with .Badge }} **{{ . }}**{{ end }}
+{{ else -}}
+No open issues.
+{{ end }}
+## Endpoints
+
+| Endpoint | Status | Latency | Availability (1h) | p95 (1h) | Max (1h) |
+|---|---|---|---|---|---|
+{{ range .Endpoints -}}
+| {{ .Name }} | {{ .Status }} | {{ ms .Latency }} | {{ printf "%.1f" .Availability }}% | {{ ms .P95 }} | {{ ms .Max }} |
+{{ end }}
+{{- range .Endpoints }}{{ $name := .Name }}{{ with .Err }}
+- {{ $name }}: {{ . }}{{ end }}{{ range .Failures }}
+- {{ $name }}: {{ . }}{{ end }}{{ range .SLOs }}
+- {{ $name }} SLO {{ .Target }}% {{ .Label }}: {{ printf "%.0f" (percent .BudgetLeft) }}% budget left, burn 1h {{ printf "%.1f" .Burn1h }}x{{ end }}{{ end }}
+
+## Unhealthy pods
+
+{{ range .Pods -}}
+### {{ .Name }} ({{ .Status }}, {{ .Restarts }} restarts)
+
+` + "```" + `
+{{ .Logs }}
+` + "```" + `
+
+{{ else -}}
+All pods healthy.
+
+{{ end -}}
+## Actions taken
+
+{{ range .Actions -}}
+- {{ .Time.Format "15:04:05" }} {{ .Text }}
+{{ else -}}
+None.
+{{ end }}
+## Notes
+
+{{ range .Notes -}}
+- {{ .Time.Format "15:04" }}{{ with .Issue }} [{{ . }}]{{ end }}{{ with .Pod }} [pod {{ . }}]{{ end }} {{ .Text }}
+{{ else -}}
+None.
+{{ end }}
+## Timeline (24h)
+
+{{ range .Timeline -}}
+- {{ .Time.Format "01-02 15:04:05" }} ` + "`[{{ .Source }}]`" + ` {{ .Text }}
+{{ else -}}
+No events.
+{{ end -}}
+`
+
+var reportFuncs = template.FuncMap{
+	"ms":      func(d time.Duration) string { return fmt.Sprintf("%dms", d.Milliseconds()) },
+	"percent": func(f float64) float64 { return f * 100 },
+}
+
+// snapshotReport collects the dashboard state; pod logs are added later by
+// exportReportCmd since they need kubectl
+func (m model) snapshotReport(now time.Time) incidentReport {
+	report := incidentReport{
+		GeneratedAt: now,
+		KubeContext: m.currentKubeContext,
+		Namespace:   m.currentNamespace,
+		SentryQuery: m.sentryQuery,
+		Issues:      m.sentryIssues,
+		Timeline:    m.timeline.filter("", 24*time.Hour, now),
+		Actions:     m.timeline.filter("action", 24*time.Hour, now),
+	}
+	for _, n := range m.notes {
+		if !n.Time.Before(now.Add(-24 * time.Hour)) {
+			report.Notes = append(report.Notes, n)
+		}
+	}
+	for _, r := range m.apiResponseTimes {
+		e := reportEndpoint{Name: r.Name, URL: r.URL, Status: r.Status, Latency: r.Latency, Err: r.Err, Failures: r.Failures, SLOs: m.sloStatuses[r.Name]}
+		e.Samples, e.Availability, e.Min, e.Avg, e.P95, e.Max = latencyStats(m.probeHistory.samples[r.Name], now.Add(-time.Hour))
+		report.Endpoints = append(report.Endpoints, e)
+	}
+	for pod, status := range m.podStatuses {
+		if podLevel(status) != "ok" {
+			report.Pods = append(report.Pods, reportPod{Name: pod, Status: status, Restarts: m.podRestarts[pod]})
+		}
+	}
+	sort.Slice(report.Pods, func(i, j int) bool { return report.Pods[i].Name < report.Pods[j].Name })
+	return report
+}
+
+// latencyStats summarises the samples since a point in time
+func latencyStats(samples []probeSample, since time.Time) (n int, availability float64, minLatency, avg, p95, maxLatency time.Duration) {
+	var latencies []time.Duration
+	var good int
+	var sum time.Duration
+	for _, s := range samples {
+		if s.Time.Before(since) {
+			continue
+		}
+		if s.Status == "OK" || s.Status == "WARN" {
+			good++
+		}
+		latencies = append(latencies, s.Latency)
+		sum += s.Latency
+	}
+	n = len(latencies)
+	if n == 0 {
+		return 0, 0, 0, 0, 0, 0
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	p95Index := (n*95+99)/100 - 1
+	return n, float64(good) * 100 / float64(n), latencies[0], sum / time.Duration(n), latencies[p95Index], latencies[n-1]
+}
+
+func reportPath(dir string, now time.Time) string {
+	if dir == "" {
+		dir = filepath.Join(stateDir(), "reports")
+	}
+	return filepath.Join(dir, "incident-"+now.Format("20060102-150405")+".md")
+}
+
+// exportReportCmd fetches the last log lines of the unhealthy pods, renders
+// the report with the configured or default template and writes it to disk
+func exportReportCmd(cfg config, report incidentReport) tea.Cmd {
+	return func() tea.Msg {
+		text := defaultReportTemplate
+		if cfg.ReportTemplate != "" {
+			data, err := os.ReadFile(cfg.ReportTemplate)
+			if err != nil {
+				return statusMsg(fmt.Sprintf("Failed to read report template: %v", err))
+			}
+			text = string(data)
+		}
+		tmpl, err := template.New("report").Funcs(reportFuncs).Parse(text)
+		if err != nil {
+			return statusMsg(fmt.Sprintf("Invalid report template: %v", err))
+		}
+
+		for i := range report.Pods {
+			report.Pods[i].Logs = tailPodLogs(report.Pods[i].Name, reportLogLines)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, report); err != nil {
+			return statusMsg(fmt.Sprintf("Failed to render report: %v", err))
+		}
+
+		path := reportPath(cfg.ReportDir, report.GeneratedAt)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return statusMsg(fmt.Sprintf("Failed to write report: %v", err))
+		}
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			return statusMsg(fmt.Sprintf("Failed to write report: %v", err))
+		}
+		return statusMsg("Incident report written to " + path)
+	}
+}
+
+// Last lines of a pod's log, or the kubectl error in their place
+func tailPodLogs(pod string, lines int) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "kubectl", "logs", pod, fmt.Sprintf("--tail=%d", lines)).CombinedOutput()
+	if err != nil {
+		return fmt.Sprintf("failed to get logs: %v\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimRight(string(output), "\n")
+}
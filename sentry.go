@@ -5,169 +5,203 @@ import (
 	"os/exec"
 	"sort"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	lipgloss "github.com/charmbracelet/lipgloss"
 )
 
 type sentryIssue struct {
-	ID       string
-	ShortID  string
-	Title    string
-	LastSeen string
-	Status   string
-	Level    string
+	ID        string
+	ShortID   string
+	Title     string
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Status    string
+	Substatus string // "regressed", "ongoing", ...; only from the API
+	Level     string
+	Count     int
+	UserCount int
+	Permalink string
+	Project   string
+	Badge     string // NEW or REGRESSED, see seenIssueStore
+	Events    []int  // hourly event counts over the last 24h
+
+	// The deploy the issue first appeared shortly after, see markDeployIssues
+	AfterDeploy string
 }
 
-type sentryErrorLogsMsg string
+type sentryErrorLogsMsg struct {
+	query    string
+	projects []string
+	issues   []sentryIssue
+}
 
-type sentryStatsMsg string
+type sentryIssueResolvedMsg sentryIssue
 
-func getSentryErrorLogsCmd() tea.Cmd {
-	return func() tea.Msg {
-		cmdTicketing := exec.Command("sentry-cli", "issues", "list", "--org", "siip", "--project", "siip-ticketing", "--query", "age:-24h is:unresolved")
-		outputTicketing, errTicketing := cmdTicketing.CombinedOutput()
-		if errTicketing != nil {
-			return errMsg(fmt.Errorf("failed to get ticketing sentry logs: %w", errTicketing))
-		}
+// Total issue count per project slug
+type sentryStatsMsg map[string]int
 
-		cmdIam := exec.Command("sentry-cli", "issues", "list", "--org", "siip", "--project", "siip-iam-service", "--query", "age:-24h is:unresolved")
-		outputIam, errIam := cmdIam.CombinedOutput()
-		if errIam != nil {
-			return errMsg(fmt.Errorf("failed to get IAM sentry logs: %w", errIam))
-		}
+// Sort keys for the Sentry pane, cycled with "s"
+var sentrySortKeys = []string{"lastSeen", "firstSeen", "events", "users", "level"}
 
-		parsedTicketing := parseSentryIssues(string(outputTicketing))
-		parsedIam := parseSentryIssues(string(outputIam))
+// The query is passed through to the Sentry API unchanged, so it accepts the
+// same search syntax as the Sentry UI.
+func getSentryErrorLogsCmd(sc sentryConfig, query, sortKey string) tea.Cmd {
+	return func() tea.Msg {
+		api, err := newSentryAPI()
+		if err != nil {
+			return errMsg(err)
+		}
+		var projects []string
+		var issues []sentryIssue
+		for _, project := range sc.Projects {
+			projectIssues, err := api.listIssues(sc.Org, project.Slug, query, sortKey)
+			if err != nil {
+				return errMsg(fmt.Errorf("failed to get %s sentry logs: %w", project.Label, err))
+			}
+			projects = append(projects, project.Slug)
+			issues = append(issues, withProject(projectIssues, project.Slug)...)
+		}
+		return sentryErrorLogsMsg{query: query, projects: projects, issues: issues}
+	}
+}
 
-		formattedOutput := formatSentryIssues(parsedTicketing) + "\n\n" + formatSentryIssues(parsedIam)
+func getSentryStatsCmd(sc sentryConfig) tea.Cmd {
+	return func() tea.Msg {
+		counts := map[string]int{}
+		for _, project := range sc.Projects {
+			cmd := exec.Command("sentry-cli", "issues", "list", "--org", sc.Org, "--project", project.Slug)
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				return errMsg(fmt.Errorf("failed to get %s sentry issues: %w", project.Label, err))
+			}
+			counts[project.Slug] = countNonEmptyLines(string(output))
+		}
+		return sentryStatsMsg(counts)
+	}
+}
 
-		return sentryErrorLogsMsg(formattedOutput)
+func withProject(issues []sentryIssue, project string) []sentryIssue {
+	for i := range issues {
+		issues[i].Project = project
 	}
+	return issues
 }
 
-func getSentryStatsCmd() tea.Cmd {
-	return func() tea.Msg {
-		cmdTicketing := exec.Command("sentry-cli", "issues", "list", "--org", "siip", "--project", "siip-ticketing")
-		outputTicketingBytes, errTicketing := cmdTicketing.CombinedOutput()
-		if errTicketing != nil {
-			return errMsg(fmt.Errorf("failed to get ticketing sentry issues: %w", errTicketing))
+// Issue counts per project with a 24h event volume sparkline when available
+func renderSentryStats(projects []sentryProjectConfig, counts map[string]int, volume map[string][]int) string {
+	var lines []string
+	for _, project := range projects {
+		count, ok := counts[project.Slug]
+		if !ok {
+			continue
 		}
-		ticketingIssueCount := countNonEmptyLines(string(outputTicketingBytes))
-
-		cmdIam := exec.Command("sentry-cli", "issues", "list", "--org", "siip", "--project", "siip-iam-service")
-		outputIamBytes, errIam := cmdIam.CombinedOutput()
-		if errIam != nil {
-			return errMsg(fmt.Errorf("failed to get IAM sentry issues: %w", errIam))
+		line := fmt.Sprintf("%s Issues (total): %d", project.Label, count)
+		if values := volume[project.Slug]; len(values) > 0 {
+			line += "  " + sparkline(values, 24)
 		}
-		iamIssueCount := countNonEmptyLines(string(outputIamBytes))
-
-		statsOutput := fmt.Sprintf("Ticketing Issues (total): %d\nIAM Issues (total): %d",
-			ticketingIssueCount,
-			iamIssueCount,
-		)
-		return sentryStatsMsg(statsOutput)
+		lines = append(lines, line)
 	}
+	return strings.Join(lines, "\n")
 }
 
-func parseSentryIssues(output string) []sentryIssue {
-	lines := strings.Split(output, "\n")
-	var issues []sentryIssue
+var sentryLevelRank = map[string]int{"fatal": 0, "error": 1, "warning": 2, "info": 3, "debug": 4}
 
-	headerLineIdx := -1
-	separatorLineIdx := -1
-	for i, line := range lines {
-		if strings.Contains(line, "Issue ID") && strings.Contains(line, "Title") {
-			headerLineIdx = i
-		} else if strings.HasPrefix(line, "+") && headerLineIdx != -1 {
-			separatorLineIdx = i
-			break
-		}
+// sortSentryIssues keeps the project grouping and badged issues on top, then
+// orders by sortKey (most recent / largest first).
+func sortSentryIssues(projects []string, issues []sentryIssue, sortKey string) {
+	order := map[string]int{}
+	for i, p := range projects {
+		order[p] = i
 	}
+	sort.SliceStable(issues, func(a, b int) bool {
+		x, y := issues[a], issues[b]
+		if order[x.Project] != order[y.Project] {
+			return order[x.Project] < order[y.Project]
+		}
+		if (x.Badge != "") != (y.Badge != "") {
+			return x.Badge != ""
+		}
+		switch sortKey {
+		case "firstSeen":
+			return x.FirstSeen.After(y.FirstSeen)
+		case "events":
+			return x.Count > y.Count
+		case "users":
+			return x.UserCount > y.UserCount
+		case "level":
+			rx, okx := sentryLevelRank[x.Level]
+			ry, oky := sentryLevelRank[y.Level]
+			if !okx {
+				rx = len(sentryLevelRank)
+			}
+			if !oky {
+				ry = len(sentryLevelRank)
+			}
+			if rx != ry {
+				return rx < ry
+			}
+			return x.LastSeen.After(y.LastSeen)
+		default:
+			return x.LastSeen.After(y.LastSeen)
+		}
+	})
+}
 
-	if headerLineIdx == -1 || separatorLineIdx == -1 {
-		return issues
+// Compact relative time such as "5m ago"
+func formatAge(t time.Time) string {
+	if t.IsZero() {
+		return "-"
 	}
-
-	header := lines[headerLineIdx]
-
-	issueIDStart := strings.Index(header, "Issue ID")
-	shortIDStart := strings.Index(header, "Short ID")
-	titleStart := strings.Index(header, "Title")
-	lastSeenStart := strings.Index(header, "Last seen")
-	statusStart := strings.Index(header, "Status")
-	levelStart := strings.Index(header, "Level")
-
-	columnStarts := []int{issueIDStart, shortIDStart, titleStart, lastSeenStart, statusStart, levelStart}
-	var validColumnStarts []int
-	for _, cs := range columnStarts {
-		if cs != -1 {
-			validColumnStarts = append(validColumnStarts, cs)
-		}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
 	}
-	sort.Ints(validColumnStarts)
-
-	for i := separatorLineIdx + 1; i < len(lines); i++ {
-		line := lines[i]
-		if strings.HasPrefix(line, "+") || strings.TrimSpace(line) == "" {
-			continue
-		}
-
-		issue := sentryIssue{}
+}
 
-		if issueIDStart != -1 && len(line) > issueIDStart {
-			issue.ID = strings.TrimSpace(extractColumn(line, issueIDStart, shortIDStart, validColumnStarts))
-		}
-		if shortIDStart != -1 && len(line) > shortIDStart {
-			issue.ShortID = strings.TrimSpace(extractColumn(line, shortIDStart, titleStart, validColumnStarts))
-		}
-		if titleStart != -1 && len(line) > titleStart {
-			issue.Title = strings.TrimSpace(extractColumn(line, titleStart, lastSeenStart, validColumnStarts))
-		}
-		if lastSeenStart != -1 && len(line) > lastSeenStart {
-			issue.LastSeen = strings.TrimSpace(extractColumn(line, lastSeenStart, statusStart, validColumnStarts))
-		}
-		if statusStart != -1 && len(line) > statusStart {
-			issue.Status = strings.TrimSpace(extractColumn(line, statusStart, levelStart, validColumnStarts))
-		}
-		if levelStart != -1 && len(line) > levelStart {
-			issue.Level = strings.TrimSpace(line[levelStart:])
+func resolveSentryIssueCmd(sc sentryConfig, issue sentryIssue) tea.Cmd {
+	return func() tea.Msg {
+		cmd := exec.Command("sentry-cli", "issues", "resolve", "--org", sc.Org, "--project", issue.Project, "-i", issue.ID)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return statusMsg(fmt.Sprintf("Failed to resolve %s: %v %s", issue.ShortID, err, strings.TrimSpace(string(out))))
 		}
-
-		issues = append(issues, issue)
+		return sentryIssueResolvedMsg(issue)
 	}
-	return issues
 }
 
-func extractColumn(line string, start int, nextStart int, validColumnStarts []int) string {
-	actualEnd := len(line)
-	for _, colStart := range validColumnStarts {
-		if colStart > start {
-			actualEnd = colStart
-			break
-		}
-	}
-	if start >= len(line) {
-		return ""
-	}
-	end := actualEnd
-	if nextStart != -1 && nextStart < end {
-		end = nextStart
+// Render issues grouped by project; selectedIndex counts across all projects
+func renderSentryIssues(projects []string, issues []sentryIssue, selectedIndex int) string {
+	var sections []string
+	offset := 0
+	for _, project := range projects {
+		var projectIssues []sentryIssue
+		for _, issue := range issues {
+			if issue.Project == project {
+				projectIssues = append(projectIssues, issue)
+			}
+		}
+		sections = append(sections, formatSentryIssues(project, projectIssues, selectedIndex-offset))
+		offset += len(projectIssues)
 	}
-	if end > len(line) {
-		end = len(line)
-	}
-	return line[start:end]
+	return strings.Join(sections, "\n\n")
 }
 
-func formatSentryIssues(issues []sentryIssue) string {
+func formatSentryIssues(project string, issues []sentryIssue, selectedIndex int) string {
 	var formatted []string
-	formatted = append(formatted, headerStyle.Render("Recent Sentry Issues:"))
+	formatted = append(formatted, headerStyle.Render("Recent Sentry Issues ("+project+"):"))
 	if len(issues) == 0 {
 		formatted = append(formatted, "  No unresolved issues found.")
 		return strings.Join(formatted, "\n")
 	}
-	for _, issue := range issues {
+	for i, issue := range issues {
 		statusStyle := defaultStyle
 		switch issue.Status {
 		case "resolved", "ignored":
@@ -184,11 +218,30 @@ func formatSentryIssues(issues []sentryIssue) string {
 		case "info", "debug":
 			levelStyle = levelInfoStyle
 		}
+		cursor := "  "
+		if i == selectedIndex {
+			cursor = highlightStyle.Render(">") + " "
+		}
+		switch issue.Badge {
+		case "NEW":
+			cursor += badgeNewStyle.Render("NEW") + " "
+		case "REGRESSED":
+			cursor += badgeRegressedStyle.Render("REGRESSED") + " "
+		}
+		if issue.AfterDeploy != "" {
+			cursor += badgeDeployStyle.Render("DEPLOY") + " "
+		}
+		spark := ""
+		if len(issue.Events) > 0 {
+			spark = " " + sparkline(issue.Events, 12)
+		}
 		formatted = append(formatted, fmt.Sprintf(
-			"  %s %s | %s | %s | %s",
+			"%s%s%s %s | %s | %s | %s",
+			cursor,
 			issueIDStyle.Render(issue.ShortID),
+			spark,
 			titleStyle.Render(issue.Title),
-			lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(issue.LastSeen),
+			lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(fmt.Sprintf("%s, %d events, %d users%s", formatAge(issue.LastSeen), issue.Count, issue.UserCount, afterDeployNote(issue))),
 			statusStyle.Render(issue.Status),
 			levelStyle.Render(issue.Level),
 		))
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sentryAPI talks to the Sentry web API for data that sentry-cli does not
+// expose. It reuses the credentials sentry-cli is configured with.
+type sentryAPI struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// Hourly events received per project slug over the last 24h, oldest first
+type sentryProjectVolumeMsg map[string][]int
+
+func newSentryAPI() (*sentryAPI, error) {
+	api := &sentryAPI{
+		baseURL: "https://sentry.io",
+		token:   os.Getenv("SENTRY_AUTH_TOKEN"),
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+	rc := readSentryCLIRC()
+	if api.token == "" {
+		api.token = rc["auth.token"]
+	}
+	if u := os.Getenv("SENTRY_URL"); u != "" {
+		api.baseURL = u
+	} else if u := rc["defaults.url"]; u != "" {
+		api.baseURL = u
+	}
+	api.baseURL = strings.TrimSuffix(api.baseURL, "/")
+	if api.token == "" {
+		return nil, errors.New("no Sentry auth token: set SENTRY_AUTH_TOKEN or run sentry-cli login")
+	}
+	return api, nil
+}
+
+// Flattened "section.key" values from ~/.sentryclirc
+func readSentryCLIRC() map[string]string {
+	values := map[string]string{}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return values
+	}
+	f, err := os.Open(filepath.Join(home, ".sentryclirc"))
+	if err != nil {
+		return values
+	}
+	defer f.Close()
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			values[section+"."+strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
+
+func (a *sentryAPI) get(path string, query url.Values, out any) error {
+	u := a.baseURL + "/api/0/" + strings.TrimPrefix(path, "/")
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// API sort parameter for each sort key; "level" is only sorted locally
+var sentryAPISort = map[string]string{
+	"lastSeen":  "date",
+	"firstSeen": "new",
+	"events":    "freq",
+	"users":     "user",
+	"level":     "date",
+}
+
+// Issues of a project matching query, with hourly event counts for the last 24h
+func (a *sentryAPI) listIssues(org, project, query, sortKey string) ([]sentryIssue, error) {
+	var raw []struct {
+		ID        string                  `json:"id"`
+		ShortID   string                  `json:"shortId"`
+		Title     string                  `json:"title"`
+		FirstSeen time.Time               `json:"firstSeen"`
+		LastSeen  time.Time               `json:"lastSeen"`
+		Status    string                  `json:"status"`
+		Substatus string                  `json:"substatus"`
+		Level     string                  `json:"level"`
+		Count     string                  `json:"count"`
+		UserCount int                     `json:"userCount"`
+		Permalink string                  `json:"permalink"`
+		Stats     map[string][][2]float64 `json:"stats"`
+	}
+	q := url.Values{"query": {query}, "statsPeriod": {"24h"}, "sort": {sentryAPISort[sortKey]}}
+	if err := a.get("projects/"+org+"/"+project+"/issues/", q, &raw); err != nil {
+		return nil, err
+	}
+	issues := make([]sentryIssue, 0, len(raw))
+	for _, r := range raw {
+		count, _ := strconv.Atoi(r.Count)
+		issues = append(issues, sentryIssue{
+			ID:        r.ID,
+			ShortID:   r.ShortID,
+			Title:     r.Title,
+			FirstSeen: r.FirstSeen,
+			LastSeen:  r.LastSeen,
+			Status:    r.Status,
+			Substatus: r.Substatus,
+			Level:     r.Level,
+			Count:     count,
+			UserCount: r.UserCount,
+			Permalink: r.Permalink,
+			Events:    statValues(r.Stats["24h"]),
+		})
+	}
+	return issues, nil
+}
+
+// Hourly count of events received by the project over the last 24h
+func (a *sentryAPI) projectVolume(org, project string) ([]int, error) {
+	var points [][2]float64
+	q := url.Values{
+		"stat":       {"received"},
+		"resolution": {"1h"},
+		"since":      {strconv.FormatInt(time.Now().Add(-24*time.Hour).Unix(), 10)},
+	}
+	if err := a.get("projects/"+org+"/"+project+"/stats/", q, &points); err != nil {
+		return nil, err
+	}
+	return statValues(points), nil
+}
+
+func statValues(points [][2]float64) []int {
+	values := make([]int, len(points))
+	for i, p := range points {
+		values[i] = int(p[1])
+	}
+	return values
+}
+
+func getSentryProjectVolumeCmd(sc sentryConfig) tea.Cmd {
+	return func() tea.Msg {
+		api, err := newSentryAPI()
+		if err != nil {
+			return errMsg(err)
+		}
+		volume := sentryProjectVolumeMsg{}
+		for _, project := range sc.Projects {
+			values, err := api.projectVolume(sc.Org, project.Slug)
+			if err != nil {
+				return errMsg(fmt.Errorf("failed to get %s event volume: %w", project.Label, err))
+			}
+			volume[project.Slug] = values
+		}
+		return volume
+	}
+}
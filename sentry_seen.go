@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// How long an issue keeps its NEW/REGRESSED badge after detection
+	issueBadgeWindow = 30 * time.Minute
+	// Records of issues absent for longer than this are dropped
+	seenIssueRetention = 30 * 24 * time.Hour
+)
+
+type seenIssue struct {
+	FirstDetected time.Time `json:"firstDetected"`
+	LastPresent   time.Time `json:"lastPresent"`
+	RegressedAt   time.Time `json:"regressedAt,omitempty"`
+	// Sentry's substatus was "regressed" in the last refresh
+	Regressed bool `json:"regressed,omitempty"`
+}
+
+// seenIssueStore remembers which Sentry issue IDs were present in earlier
+// refreshes, including those of previous runs.
+type seenIssueStore struct {
+	path        string
+	LastRefresh time.Time            `json:"lastRefresh"`
+	Issues      map[string]seenIssue `json:"issues"`
+}
+
+func seenIssuesPath() string {
+	return filepath.Join(stateDir(), "sentry-seen.json")
+}
+
+func loadSeenIssueStore(path string) *seenIssueStore {
+	store := &seenIssueStore{path: path, Issues: map[string]seenIssue{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return store
+	}
+	if err := json.Unmarshal(data, store); err != nil || store.Issues == nil {
+		store.Issues = map[string]seenIssue{}
+		store.LastRefresh = time.Time{}
+	}
+	return store
+}
+
+func (s *seenIssueStore) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// observe records a refresh and sets the Badge of each issue: NEW for IDs
+// never seen before, REGRESSED when Sentry marks an issue regressed (a
+// resolved issue that came back) and it was not already. An issue merely
+// missing from the previous refresh, e.g. because it dropped out of the
+// query's age window, is not a regression. The very first refresh only
+// establishes a baseline.
+func (s *seenIssueStore) observe(issues []sentryIssue, now time.Time) error {
+	baseline := s.LastRefresh.IsZero()
+	for i := range issues {
+		rec, known := s.Issues[issues[i].ID]
+		if !known && !baseline {
+			rec.FirstDetected = now
+		}
+		regressed := issues[i].Substatus == "regressed"
+		if regressed && !rec.Regressed && !baseline {
+			rec.RegressedAt = now
+		}
+		rec.Regressed = regressed
+		rec.LastPresent = now
+		s.Issues[issues[i].ID] = rec
+	}
+	for id, rec := range s.Issues {
+		if now.Sub(rec.LastPresent) > seenIssueRetention {
+			delete(s.Issues, id)
+		}
+	}
+	s.LastRefresh = now
+	s.applyBadges(issues, now)
+	return s.save()
+}
+
+// applyBadges sets badges from what is already recorded without recording
+// anything, for result sets of ad-hoc queries.
+func (s *seenIssueStore) applyBadges(issues []sentryIssue, now time.Time) {
+	for i := range issues {
+		rec := s.Issues[issues[i].ID]
+		issues[i].Badge = ""
+		if now.Sub(rec.RegressedAt) < issueBadgeWindow {
+			issues[i].Badge = "REGRESSED"
+		} else if now.Sub(rec.FirstDetected) < issueBadgeWindow {
+			issues[i].Badge = "NEW"
+		}
+	}
+}
+
+func countBadgedIssues(issues []sentryIssue) (newCount, regressedCount int) {
+	for _, issue := range issues {
+		switch issue.Badge {
+		case "NEW":
+			newCount++
+		case "REGRESSED":
+			regressedCount++
+		}
+	}
+	return newCount, regressedCount
+}
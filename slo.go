@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Multi-window burn rate alerting as described in the Google SRE workbook: a
+// burn counts only when both the long and the short window exceed the
+// threshold, so it clears quickly once the endpoint recovers.
+const (
+	fastBurnRate = 14.4 // 2% of a 30 day budget within an hour
+	slowBurnRate = 6.0  // 5% of a 30 day budget within six hours
+)
+
+var (
+	fastBurnWindows = [2]time.Duration{time.Hour, 5 * time.Minute}
+	slowBurnWindows = [2]time.Duration{6 * time.Hour, 30 * time.Minute}
+)
+
+// probeSample is the outcome of one probe of one check
+type probeSample struct {
+	Time    time.Time
+	Status  string
+	Latency time.Duration
+}
+
+// probeHistory keeps recent samples per check name, oldest first
+type probeHistory struct {
+	samples map[string][]probeSample
+}
+
+func newProbeHistory() *probeHistory {
+	return &probeHistory{samples: map[string][]probeSample{}}
+}
+
+// record appends a probe round and drops samples older than retention
+func (h *probeHistory) record(results []endpointResult, now time.Time, retention time.Duration) {
+	for _, r := range results {
+		h.samples[r.Name] = append(h.samples[r.Name], probeSample{Time: now, Status: r.Status, Latency: r.Latency})
+	}
+	cutoff := now.Add(-retention)
+	for name, samples := range h.samples {
+		i := 0
+		for i < len(samples) && samples[i].Time.Before(cutoff) {
+			i++
+		}
+		h.samples[name] = samples[i:]
+	}
+}
+
+// Longest window any SLO needs, at least the slow burn window
+func sloRetention(checks []healthCheckConfig) time.Duration {
+	retention := slowBurnWindows[0]
+	for _, c := range checks {
+		if c.SLO != nil && c.SLO.window() > retention {
+			retention = c.SLO.window()
+		}
+	}
+	return retention
+}
+
+// sloStatus is the state of one SLI of a check
+type sloStatus struct {
+	Label      string
+	Target     float64 // percent
+	Samples    int     // within the budget window
+	BudgetLeft float64 // fraction of the error budget remaining; negative when exhausted
+	Burn1h     float64
+	Burn5m     float64
+	State      string // OK, SLOW_BURN or FAST_BURN
+}
+
+func (s *sloConfig) window() time.Duration {
+	return time.Duration(s.WindowHours) * time.Hour
+}
+
+// evaluateSLOs computes the availability and latency SLOs configured for
+// check from its samples
+func evaluateSLOs(check healthCheckConfig, samples []probeSample, now time.Time) []sloStatus {
+	if check.SLO == nil {
+		return nil
+	}
+	var statuses []sloStatus
+	if check.SLO.Availability > 0 {
+		good := func(s probeSample) (bool, bool) { return s.Status == "OK" || s.Status == "WARN", true }
+		statuses = append(statuses, evaluateSLI("avail", check.SLO.Availability, check.SLO.window(), samples, good, now))
+	}
+	if check.SLO.LatencyMS > 0 && check.SLO.LatencyTarget > 0 {
+		limit := time.Duration(check.SLO.LatencyMS) * time.Millisecond
+		good := func(s probeSample) (bool, bool) {
+			switch s.Status {
+			case "ERROR":
+				return false, false // no response, counted by availability only
+			case "TIMEOUT":
+				return false, true
+			}
+			return s.Latency <= limit, true
+		}
+		statuses = append(statuses, evaluateSLI(fmt.Sprintf("<%dms", check.SLO.LatencyMS), check.SLO.LatencyTarget, check.SLO.window(), samples, good, now))
+	}
+	return statuses
+}
+
+// good reports whether a sample met the objective and whether it counts at all
+func evaluateSLI(label string, target float64, window time.Duration, samples []probeSample, good func(probeSample) (bool, bool), now time.Time) sloStatus {
+	budget := 1 - target/100
+	burnRate := func(d time.Duration) (float64, int) {
+		var total, bad int
+		cutoff := now.Add(-d)
+		for i := len(samples) - 1; i >= 0 && !samples[i].Time.Before(cutoff); i-- {
+			ok, counts := good(samples[i])
+			if !counts {
+				continue
+			}
+			total++
+			if !ok {
+				bad++
+			}
+		}
+		if total == 0 || budget <= 0 {
+			return 0, total
+		}
+		return float64(bad) / float64(total) / budget, total
+	}
+
+	status := sloStatus{Label: label, Target: target, State: "OK"}
+	burnWindow, n := burnRate(window)
+	status.Samples = n
+	status.BudgetLeft = 1 - burnWindow
+	status.Burn1h, _ = burnRate(fastBurnWindows[0])
+	status.Burn5m, _ = burnRate(fastBurnWindows[1])
+	slowLong, _ := burnRate(slowBurnWindows[0])
+	slowShort, _ := burnRate(slowBurnWindows[1])
+	switch {
+	case status.Burn1h >= fastBurnRate && status.Burn5m >= fastBurnRate:
+		status.State = "FAST_BURN"
+	case slowLong >= slowBurnRate && slowShort >= slowBurnRate:
+		status.State = "SLOW_BURN"
+	}
+	return status
+}
+
+// Most severe burn state across the SLOs of a check
+func worstSLOState(statuses []sloStatus) string {
+	worst := "OK"
+	for _, s := range statuses {
+		if s.State == "FAST_BURN" || (s.State == "SLOW_BURN" && worst == "OK") {
+			worst = s.State
+		}
+	}
+	return worst
+}
+
+func renderBudgetBar(left float64, width int) string {
+	filled := int(math.Round(math.Max(0, math.Min(1, left)) * float64(width)))
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// "  SLO 99.9% avail [██████░░░░] 62% budget left · burn 1h 3.2x 5m 0.0x"
+func renderSLOStatus(s sloStatus) string {
+	label := fmt.Sprintf("  SLO %g%% %s ", s.Target, s.Label)
+	if s.Samples == 0 {
+		return label + logViewerFooterStyle.Render("no data yet")
+	}
+	style := statusResolvedStyle
+	switch {
+	case s.State == "FAST_BURN" || s.BudgetLeft <= 0:
+		style = statusUnresolvedStyle
+	case s.State == "SLOW_BURN" || s.BudgetLeft < 0.25:
+		style = pendingStyle
+	}
+	line := label + style.Render("["+renderBudgetBar(s.BudgetLeft, 10)+"]")
+	if s.BudgetLeft <= 0 {
+		line += " budget exhausted"
+	} else {
+		line += fmt.Sprintf(" %.0f%% budget left", s.BudgetLeft*100)
+	}
+	line += fmt.Sprintf(" · burn 1h %.1fx 5m %.1fx", s.Burn1h, s.Burn5m)
+	if s.State != "OK" {
+		line += " " + style.Render(strings.ReplaceAll(s.State, "_", " "))
+	}
+	return line
+}
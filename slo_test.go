@@ -0,0 +1,102 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// minuteSamples returns one sample per minute over the last span, oldest
+// first; status decides the outcome by the age of the sample
+func minuteSamples(now time.Time, span time.Duration, status func(age time.Duration) string) []probeSample {
+	var samples []probeSample
+	for age := span; age >= 0; age -= time.Minute {
+		samples = append(samples, probeSample{Time: now.Add(-age), Status: status(age), Latency: 100 * time.Millisecond})
+	}
+	return samples
+}
+
+// every returns a status func that fails one sample in n
+func every(n int) func(time.Duration) string {
+	return func(age time.Duration) string {
+		if int(age/time.Minute)%n == 0 {
+			return "FAIL"
+		}
+		return "OK"
+	}
+}
+
+func TestEvaluateSLI(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	ok := func(time.Duration) string { return "OK" }
+	tests := []struct {
+		name       string
+		target     float64
+		samples    []probeSample
+		state      string
+		budgetLeft float64
+		burn1h     float64
+		burn5m     float64
+	}{
+		{"empty window", 99, nil, "OK", 1, 0, 0},
+		{"samples outside the window", 99, minuteSamples(now.Add(-48*time.Hour), time.Hour, every(1)), "OK", 1, 0, 0},
+		{"100% success", 99, minuteSamples(now, 24*time.Hour, ok), "OK", 1, 0, 0},
+		{"WARN counts as success", 99, minuteSamples(now, time.Hour, func(time.Duration) string { return "WARN" }), "OK", 1, 0, 0},
+		{"no error budget", 100, minuteSamples(now, time.Hour, every(2)), "OK", 1, 0, 0},
+		{"within budget", 99, minuteSamples(now, 24*time.Hour, every(200)), "OK", 1 - 8.0/1441/0.01, 1.0 / 61 / 0.01, 1.0 / 6 / 0.01},
+		{"fast burn", 99, minuteSamples(now, time.Hour, every(5)), "FAST_BURN", 1 - 13.0/61/0.01, 13.0 / 61 / 0.01, 2.0 / 6 / 0.01},
+		{"fast burn that recovered", 99, minuteSamples(now, time.Hour, func(age time.Duration) string {
+			if age >= 40*time.Minute {
+				return "FAIL"
+			}
+			return "OK"
+		}), "OK", 1 - 21.0/61/0.01, 21.0 / 61 / 0.01, 0},
+		{"slow burn", 99, minuteSamples(now, 6*time.Hour, every(10)), "SLOW_BURN", 1 - 37.0/361/0.01, 7.0 / 61 / 0.01, 1.0 / 6 / 0.01},
+		{"slow burn below the fast threshold only", 99, minuteSamples(now, 6*time.Hour, every(20)), "OK", 1 - 19.0/361/0.01, 4.0 / 61 / 0.01, 1.0 / 6 / 0.01},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := healthCheckConfig{SLO: &sloConfig{Availability: tt.target, WindowHours: 24}}
+			statuses := evaluateSLOs(check, tt.samples, now)
+			if len(statuses) != 1 {
+				t.Fatalf("got %d statuses, want 1", len(statuses))
+			}
+			s := statuses[0]
+			if s.State != tt.state || !near(s.BudgetLeft, tt.budgetLeft) || !near(s.Burn1h, tt.burn1h) || !near(s.Burn5m, tt.burn5m) {
+				t.Errorf("got %s budget %.3f burn %.2f/%.2f, want %s budget %.3f burn %.2f/%.2f",
+					s.State, s.BudgetLeft, s.Burn1h, s.Burn5m, tt.state, tt.budgetLeft, tt.burn1h, tt.burn5m)
+			}
+			if math.IsNaN(s.BudgetLeft) || math.IsInf(s.Burn1h, 0) {
+				t.Errorf("got %+v", s)
+			}
+		})
+	}
+}
+
+func TestEvaluateLatencySLI(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	samples := []probeSample{
+		{Time: now.Add(-3 * time.Minute), Status: "OK", Latency: 80 * time.Millisecond},
+		{Time: now.Add(-2 * time.Minute), Status: "FAIL", Latency: 400 * time.Millisecond}, // answered, so it counts
+		{Time: now.Add(-time.Minute), Status: "ERROR"},                                     // no response
+		{Time: now, Status: "TIMEOUT"},
+	}
+	check := healthCheckConfig{SLO: &sloConfig{Availability: 50, LatencyMS: 200, LatencyTarget: 50, WindowHours: 1}}
+	statuses := evaluateSLOs(check, samples, now)
+	if len(statuses) != 2 {
+		t.Fatalf("got %+v, want availability and latency", statuses)
+	}
+	if avail := statuses[0]; avail.Label != "avail" || avail.Samples != 4 || !near(avail.BudgetLeft, 1-0.75/0.5) {
+		t.Errorf("availability %+v", avail)
+	}
+	if latency := statuses[1]; latency.Label != "<200ms" || latency.Samples != 3 || !near(latency.BudgetLeft, 1-(2.0/3)/0.5) {
+		t.Errorf("latency %+v", latency)
+	}
+	if evaluateSLOs(healthCheckConfig{}, samples, now) != nil {
+		t.Error("a check without SLO has no statuses")
+	}
+}
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
@@ -0,0 +1,52 @@
+package main
+
+import "strings"
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders values as block characters, summing neighbouring values
+// so the result is at most width runes wide. Spikes (last bucket at least
+// twice the average of the earlier ones) are rendered in the error colour.
+func sparkline(values []int, width int) string {
+	if len(values) == 0 || width <= 0 {
+		return ""
+	}
+	buckets := values
+	if len(values) > width {
+		buckets = make([]int, width)
+		for i, v := range values {
+			buckets[i*width/len(values)] += v
+		}
+	}
+	maxValue := 0
+	for _, v := range buckets {
+		if v > maxValue {
+			maxValue = v
+		}
+	}
+	var b strings.Builder
+	for _, v := range buckets {
+		idx := 0
+		if maxValue > 0 {
+			idx = v * (len(sparkBlocks) - 1) / maxValue
+		}
+		b.WriteRune(sparkBlocks[idx])
+	}
+	if isSpiking(buckets) {
+		return sparklineSpikeStyle.Render(b.String())
+	}
+	return sparklineStyle.Render(b.String())
+}
+
+func isSpiking(buckets []int) bool {
+	if len(buckets) < 2 {
+		return false
+	}
+	last := buckets[len(buckets)-1]
+	sum := 0
+	for _, v := range buckets[:len(buckets)-1] {
+		sum += v
+	}
+	avg := float64(sum) / float64(len(buckets)-1)
+	return last > 0 && float64(last) >= 2*avg
+}
@@ -15,6 +15,17 @@ var (
 	levelInfoStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("12"))            // Blue
 )
 
+var (
+	badgeNewStyle       = lipgloss.NewStyle().Bold(true).Background(lipgloss.Color("11")).Foreground(lipgloss.Color("0")) // Black on yellow
+	badgeRegressedStyle = lipgloss.NewStyle().Bold(true).Background(lipgloss.Color("9")).Foreground(lipgloss.Color("15")) // White on red
+	badgeDeployStyle    = lipgloss.NewStyle().Bold(true).Background(lipgloss.Color("14")).Foreground(lipgloss.Color("0")) // Black on cyan
+)
+
+var (
+	sparklineStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("14")) // Cyan
+	sparklineSpikeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))  // Red
+)
+
 var (
 	paneTitleStyle = lipgloss.NewStyle().Bold(true).Align(lipgloss.Center).Padding(0, 2)
 )
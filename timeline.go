@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+var (
+	timelineSources = []string{"", "sentry", "endpoint", "pod", "deploy", "action", "note"} // "" shows all
+	timelineRanges  = []time.Duration{time.Hour, 6 * time.Hour, 24 * time.Hour, 7 * 24 * time.Hour, 0}
+)
+
+// timelineEvent is one line of the incident timeline
+type timelineEvent struct {
+	Time   time.Time
+	Source string // "sentry", "endpoint", "pod", "deploy", "action" or "note"
+	Level  string // "error", "warn", "ok" or "info"
+	Text   string
+}
+
+// timeline turns history records into events. It keeps the state needed to
+// spot transitions, so it is fed the stored history at startup and every
+// new record afterwards.
+type timeline struct {
+	events         []timelineEvent
+	endpointStatus map[string]string
+	replicaSets    map[string]bool
+}
+
+func newTimeline() *timeline {
+	return &timeline{endpointStatus: map[string]string{}, replicaSets: map[string]bool{}}
+}
+
+func (t *timeline) add(records []historyRecord) {
+	for _, r := range records {
+		switch r.Kind {
+		case "probe":
+			prev := t.endpointStatus[r.Name]
+			t.endpointStatus[r.Name] = r.Status
+			if prev == r.Status || (prev == "" && r.Status == "OK") {
+				continue
+			}
+			text := fmt.Sprintf("%s is %s", r.Name, r.Status)
+			if prev != "" {
+				text = fmt.Sprintf("%s %s → %s", r.Name, prev, r.Status)
+			}
+			t.events = append(t.events, timelineEvent{Time: r.Time, Source: "endpoint", Level: endpointLevel(r.Status), Text: text})
+		case "pod":
+			t.addPodRecord(r)
+		case "issue":
+			level := "warn"
+			if r.Status == "REGRESSED" {
+				level = "error"
+			}
+			t.events = append(t.events, timelineEvent{Time: r.Time, Source: "sentry", Level: level, Text: fmt.Sprintf("%s %s: %s", r.Status, r.Name, r.Title)})
+		case "deploy":
+			t.events = append(t.events, timelineEvent{Time: r.Time, Source: "deploy", Level: "info", Text: r.Title})
+		case "action":
+			t.events = append(t.events, timelineEvent{Time: r.Time, Source: "action", Level: "info", Text: r.Title})
+		}
+	}
+}
+
+func (t *timeline) addNotes(notes []incidentNote) {
+	for _, n := range notes {
+		t.events = append(t.events, timelineEvent{Time: n.Time, Source: "note", Level: "info", Text: n.summary()})
+	}
+}
+
+func (t *timeline) addPodRecord(r historyRecord) {
+	rs := replicaSetOf(r.Name)
+	newReplicaSet := rs != "" && !t.replicaSets[r.Scope+"/"+rs]
+	if rs != "" {
+		t.replicaSets[r.Scope+"/"+rs] = true
+	}
+	if r.Baseline {
+		return
+	}
+	event := timelineEvent{Time: r.Time, Source: "pod", Level: podLevel(r.Status)}
+	switch {
+	case r.Status == "Deleted":
+		event.Level = "info"
+		event.Text = fmt.Sprintf("Pod %s deleted (was %s)", r.Name, r.From)
+	case r.Status == "Evicted":
+		event.Text = fmt.Sprintf("Pod %s evicted", r.Name)
+	case r.From == "" && newReplicaSet:
+		event.Level = "info"
+		event.Text = fmt.Sprintf("New ReplicaSet %s: pod %s %s", rs, r.Name, r.Status)
+	case r.From == "":
+		event.Text = fmt.Sprintf("Pod %s created (%s)", r.Name, r.Status)
+	case r.From == r.Status:
+		event.Level = "warn"
+		event.Text = fmt.Sprintf("Pod %s restarted (%d restarts)", r.Name, r.Restarts)
+	default:
+		event.Text = fmt.Sprintf("Pod %s %s → %s", r.Name, r.From, r.Status)
+		if r.Restarts > 0 {
+			event.Text += fmt.Sprintf(" (%d restarts)", r.Restarts)
+		}
+	}
+	t.events = append(t.events, event)
+}
+
+// replicaSetOf returns the ReplicaSet part of a Deployment pod name
+// (<deployment>-<pod-template-hash>-<suffix>), or "" for other pods
+func replicaSetOf(pod string) string {
+	parts := strings.Split(pod, "-")
+	if len(parts) < 3 {
+		return ""
+	}
+	hash, suffix := parts[len(parts)-2], parts[len(parts)-1]
+	if len(suffix) != 5 || len(hash) < 6 || len(hash) > 10 {
+		return ""
+	}
+	return strings.Join(parts[:len(parts)-1], "-")
+}
+
+func endpointLevel(status string) string {
+	switch status {
+	case "OK":
+		return "ok"
+	case "WARN":
+		return "warn"
+	}
+	return "error"
+}
+
+func podLevel(status string) string {
+	for _, rule := range defaultAlertRules() {
+		if rule.Source == "pod" && rule.matches("", status) {
+			return "error"
+		}
+	}
+	switch status {
+	case "Running", "Completed", "Succeeded":
+		return "ok"
+	}
+	return "warn"
+}
+
+// filter returns the events of source ("" for all) within the last span (0
+// for everything), oldest first
+func (t *timeline) filter(source string, span time.Duration, now time.Time) []timelineEvent {
+	var events []timelineEvent
+	for _, e := range t.events {
+		if source != "" && e.Source != source {
+			continue
+		}
+		if span > 0 && e.Time.Before(now.Add(-span)) {
+			continue
+		}
+		events = append(events, e)
+	}
+	// notes are added separately from the history and may be out of order
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events
+}
+
+func renderTimelineEvents(events []timelineEvent) string {
+	if len(events) == 0 {
+		return logViewerFooterStyle.Render("No events in this range")
+	}
+	lines := make([]string, 0, len(events))
+	for _, e := range events {
+		style := levelInfoStyle
+		switch e.Level {
+		case "error":
+			style = statusUnresolvedStyle
+		case "warn":
+			style = pendingStyle
+		case "ok":
+			style = statusResolvedStyle
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s %s", e.Time.Local().Format("Jan 02 15:04:05"), style.Render(fmt.Sprintf("%-8s", e.Source)), e.Text))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func timelineRangeLabel(span time.Duration) string {
+	switch {
+	case span == 0:
+		return "all history"
+	case span >= 24*time.Hour:
+		return fmt.Sprintf("last %dd", int(span.Hours()/24))
+	}
+	return fmt.Sprintf("last %dh", int(span.Hours()))
+}
+
+// Size the timeline viewport and refill it from the current filter
+func (m *model) refreshTimeline() {
+	header := lipgloss.Height(logViewerHeaderStyle.Render(" "))
+	footer := lipgloss.Height(logViewerFooterStyle.Render(" "))
+	if m.timelineViewport.Width != m.width || m.timelineViewport.Height != m.height-header-footer {
+		m.timelineViewport = viewport.New(m.width, m.height-header-footer)
+		m.timelineViewport.YPosition = header
+	}
+	atBottom := m.timelineViewport.AtBottom()
+	m.timelineViewport.SetContent(renderTimelineEvents(m.timeline.filter(timelineSources[m.timelineSource], timelineRanges[m.timelineRange], time.Now())))
+	if atBottom {
+		m.timelineViewport.GotoBottom()
+	}
+}
+
+func (m model) timelineView() string {
+	source := timelineSources[m.timelineSource]
+	if source == "" {
+		source = "all"
+	}
+	header := logViewerHeaderStyle.Render(fmt.Sprintf("Incident timeline · source: %s · %s", source, timelineRangeLabel(timelineRanges[m.timelineRange])))
+	footer := logViewerFooterStyle.Render("f: Source | r: Time Range | Scroll with arrow keys / mouse wheel | Esc: Back")
+	return lipgloss.JoinVertical(lipgloss.Left, header, m.timelineViewport.View(), footer)
+}
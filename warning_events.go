@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// warningGroup is all Warning events of one involved object
+type warningGroup struct {
+	Namespace string
+	Kind      string
+	Name      string
+	Reasons   []string // distinct, most recent first
+	Message   string   // of the most recent event
+	Count     int
+	LastSeen  time.Time
+}
+
+// warningWatchMsg is an update from one of the running event watches; each
+// message re-arms the wait for the next
+type warningWatchMsg struct {
+	generation int    // of the watches that sent it, see restartWarningWatch
+	scope      string // namespace, or "*" for all
+	reset      bool   // the watch (re)started and lists all events again
+	action     string // ADDED, MODIFIED or DELETED
+	uid        string
+	event      kubeEvent
+	err        error // the watch ended; it is restarted after a pause
+}
+
+// Pause before restarting a watch that failed
+const warningWatchRetry = 5 * time.Second
+
+// warningWatchScopes are the watched namespaces; no namespaces means the
+// current one and "*" all
+func warningWatchScopes(namespaces []string, current string) []string {
+	if slices.Contains(namespaces, "*") {
+		return []string{"*"}
+	}
+	if len(namespaces) == 0 {
+		return []string{current}
+	}
+	return namespaces
+}
+
+// watchWarningEvents streams the Warning events of one scope into ch with
+// `kubectl get events --watch` until ctx is cancelled. kubectl exits when
+// the API server ends the watch, so it is restarted and lists again.
+func watchWarningEvents(ctx context.Context, ch chan<- warningWatchMsg, generation int, kubeContext, scope string) {
+	send := func(msg warningWatchMsg) bool {
+		msg.generation, msg.scope = generation, scope
+		select {
+		case ch <- msg:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	for {
+		args := []string{"get", "events", "--watch", "--output-watch-events", "-o", "json", "--field-selector", "type=Warning", "--context", kubeContext}
+		if scope == "*" {
+			args = append(args, "--all-namespaces")
+		} else {
+			args = append(args, "--namespace", scope)
+		}
+		cmd := exec.CommandContext(ctx, "kubectl", args...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		stdout, err := cmd.StdoutPipe()
+		if err == nil {
+			err = cmd.Start()
+		}
+		if err == nil {
+			if !send(warningWatchMsg{reset: true}) {
+				_ = cmd.Wait()
+				return
+			}
+			decoder := json.NewDecoder(stdout)
+			for {
+				var change struct {
+					Type   string          `json:"type"`
+					Object kubeEventObject `json:"object"`
+				}
+				if decoder.Decode(&change) != nil {
+					break
+				}
+				if !send(warningWatchMsg{action: change.Type, uid: change.Object.Metadata.UID, event: change.Object.event()}) {
+					_ = cmd.Wait()
+					return
+				}
+			}
+			err = cmd.Wait()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if text := strings.TrimSpace(stderr.String()); text != "" {
+				err = fmt.Errorf("%s", text)
+			}
+			if !send(warningWatchMsg{err: fmt.Errorf("failed to watch events: %w", err)}) {
+				return
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(warningWatchRetry):
+		}
+	}
+}
+
+func waitWarningWatchCmd(ch <-chan warningWatchMsg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+// restartWarningWatch (re)starts the watches once the kube context and
+// namespace are known, and whenever they change. Messages of stopped watches
+// are told apart by their generation.
+func (m *model) restartWarningWatch() {
+	if m.currentKubeContext == "" || m.currentNamespace == "" {
+		return
+	}
+	scopes := warningWatchScopes(m.cfg.WatchedNamespaces, m.currentNamespace)
+	key := m.currentKubeContext + "/" + strings.Join(scopes, ",")
+	if key == m.warningWatchKey {
+		return
+	}
+	m.stopWarningWatch()
+	m.warningWatchKey = key
+	m.warningGeneration++
+	m.warningEventSet = map[string]map[string]kubeEvent{}
+	m.warningEvents = nil
+	m.warningEventsErr = ""
+	ctx, cancel := context.WithCancel(context.Background())
+	m.warningCancel = cancel
+	for _, scope := range scopes {
+		go watchWarningEvents(ctx, m.warningWatchCh, m.warningGeneration, m.currentKubeContext, scope)
+	}
+}
+
+func (m *model) stopWarningWatch() {
+	if m.warningCancel != nil {
+		m.warningCancel()
+		m.warningCancel = nil
+	}
+}
+
+func (m model) handleWarningWatchMsg(msg warningWatchMsg) (tea.Model, tea.Cmd) {
+	wait := waitWarningWatchCmd(m.warningWatchCh)
+	if msg.generation != m.warningGeneration {
+		return m, wait
+	}
+	switch {
+	case msg.err != nil:
+		m.warningEventsErr = msg.err.Error()
+		return m, wait
+	case msg.reset:
+		m.warningEventSet[msg.scope] = map[string]kubeEvent{}
+	case msg.action == "DELETED":
+		delete(m.warningEventSet[msg.scope], msg.uid)
+	default:
+		m.warningEventsErr = ""
+		if m.warningEventSet[msg.scope] == nil {
+			m.warningEventSet[msg.scope] = map[string]kubeEvent{}
+		}
+		m.warningEventSet[msg.scope][msg.uid] = msg.event
+	}
+	var events []kubeEvent
+	for _, scope := range m.warningEventSet {
+		for _, e := range scope {
+			events = append(events, e)
+		}
+	}
+	m.warningEvents = groupWarningEvents(events)
+	if m.selectedEvent >= len(m.warningEvents) {
+		m.selectedEvent = 0
+	}
+	return m, wait
+}
+
+// groupWarningEvents de-duplicates events by involved object, most recently
+// seen object first
+func groupWarningEvents(events []kubeEvent) []warningGroup {
+	// oldest first, so later events overwrite the message
+	sort.SliceStable(events, func(i, j int) bool { return events[i].LastSeen.Before(events[j].LastSeen) })
+	byObject := map[string]*warningGroup{}
+	var groups []*warningGroup
+	for _, e := range events {
+		key := e.Namespace + "/" + e.Kind + "/" + e.Name
+		g := byObject[key]
+		if g == nil {
+			g = &warningGroup{Namespace: e.Namespace, Kind: e.Kind, Name: e.Name}
+			byObject[key] = g
+			groups = append(groups, g)
+		}
+		g.Count += e.Count
+		g.LastSeen = e.LastSeen
+		g.Message = e.Message
+		reasons := []string{e.Reason}
+		for _, r := range g.Reasons {
+			if r != e.Reason {
+				reasons = append(reasons, r)
+			}
+		}
+		g.Reasons = reasons
+	}
+	result := make([]warningGroup, len(groups))
+	for i, g := range groups {
+		result[i] = *g
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].LastSeen.After(result[j].LastSeen) })
+	return result
+}
+
+func renderWarningEvents(groups []warningGroup, errText string, selected int, namespace string, width int) string {
+	if errText != "" {
+		return statusUnresolvedStyle.Render(errText)
+	}
+	if len(groups) == 0 {
+		return statusResolvedStyle.Render("No Warning events")
+	}
+	lines := make([]string, 0, len(groups))
+	for i, g := range groups {
+		object := g.Kind + " " + g.Name
+		if g.Namespace != namespace {
+			object = g.Kind + " " + g.Namespace + "/" + g.Name
+		}
+		reasons := strings.Join(g.Reasons, ",")
+		prefix := fmt.Sprintf("%s x%-3d %s ", g.LastSeen.Local().Format("15:04:05"), g.Count, object)
+		message := strings.Join(strings.Fields(g.Message), " ")
+		if room := width - len([]rune(prefix+reasons+": ")); width > 0 && len([]rune(message)) > room {
+			if room < 1 {
+				room = 1
+			}
+			message = string([]rune(message)[:room-1]) + "…"
+		}
+		var line string
+		if i == selected {
+			line = highlightStyle.Render(prefix + reasons + ": " + message)
+		} else {
+			line = prefix + statusUnresolvedStyle.Render(reasons) + ": " + message
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// jumpToEventPod selects the pod the selected event is about in the pods pane
+func (m model) jumpToEventPod() (tea.Model, tea.Cmd) {
+	if m.selectedEvent >= len(m.warningEvents) {
+		return m, nil
+	}
+	g := m.warningEvents[m.selectedEvent]
+	if g.Kind != "Pod" {
+		m.statusMessage = fmt.Sprintf("%s %s is not a pod", g.Kind, g.Name)
+		return m, nil
+	}
+	if g.Namespace == m.currentNamespace {
+		for i, pod := range m.podNames {
+			if pod == g.Name {
+				m.selectedPane = 2
+				m.selectedPodIndex = i
+				return m, nil
+			}
+		}
+	}
+	m.statusMessage = fmt.Sprintf("Pod %s/%s is not in the pods pane", g.Namespace, g.Name)
+	return m, nil
+}
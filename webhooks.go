@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type notification struct {
+	Event       string    `json:"event"` // "alert" or "resolved"
+	Title       string    `json:"title"`
+	ShortID     string    `json:"sentryShortId,omitempty"`
+	Endpoint    string    `json:"endpoint,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	Pod         string    `json:"pod,omitempty"`
+	KubeContext string    `json:"kubeContext,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+// Message shown in the key hints bar
+type statusMsg string
+
+// Initial delay between webhook retries, doubled after every failed attempt
+var webhookBackoff = time.Second
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+func (n notification) text() string {
+	prefix := "🔥 *ALERT*"
+	if n.Event == "resolved" {
+		prefix = "✅ *RESOLVED*"
+	}
+	parts := []string{prefix}
+	if n.ShortID != "" {
+		parts = append(parts, n.ShortID)
+	}
+	parts = append(parts, n.Title)
+	var details []string
+	if n.Endpoint != "" {
+		details = append(details, "endpoint: "+n.Endpoint)
+	}
+	if n.Pod != "" {
+		details = append(details, "pod: "+n.Pod)
+	}
+	if n.Status != "" {
+		details = append(details, "status: "+n.Status)
+	}
+	if n.KubeContext != "" {
+		details = append(details, "context: "+n.KubeContext)
+	}
+	text := strings.Join(parts, " ")
+	if len(details) > 0 {
+		text += "\n" + strings.Join(details, " | ")
+	}
+	return text
+}
+
+func buildWebhookPayload(format string, n notification) ([]byte, error) {
+	switch format {
+	case "slack":
+		// Slack incoming webhooks and Teams connectors both accept a plain text payload
+		return json.Marshal(map[string]string{"text": n.text()})
+	case "json":
+		return json.Marshal(n)
+	default:
+		return nil, fmt.Errorf("unknown webhook format %q", format)
+	}
+}
+
+// postWebhook delivers body to the hook, retrying network errors, 429 and 5xx
+// responses with exponential backoff.
+func postWebhook(wh webhookConfig, body []byte) error {
+	delay := webhookBackoff
+	var lastErr error
+	for attempt := 0; attempt <= wh.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		resp, err := webhookClient.Post(wh.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("unexpected status %s", resp.Status)
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			return lastErr
+		}
+	}
+	return lastErr
+}
+
+func sendNotificationCmd(cfg config, n notification) tea.Cmd {
+	if len(cfg.Webhooks) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		var failed []string
+		for _, wh := range cfg.Webhooks {
+			body, err := buildWebhookPayload(wh.Format, n)
+			if err == nil {
+				if cfg.DryRun {
+					err = writeDryRunPayload(wh, body)
+				} else {
+					err = postWebhook(wh, body)
+				}
+			}
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %v", wh.Name, err))
+			}
+		}
+		if len(failed) > 0 {
+			return statusMsg("Webhook delivery failed - " + strings.Join(failed, "; "))
+		}
+		if cfg.DryRun {
+			return statusMsg(fmt.Sprintf("Dry run: %q written to %s", n.Title, dryRunLogPath()))
+		}
+		return statusMsg(fmt.Sprintf("Notified %d webhook(s): %s", len(cfg.Webhooks), n.Title))
+	}
+}
+
+func dryRunLogPath() string {
+	return filepath.Join(stateDir(), "webhooks-dry-run.log")
+}
+
+func writeDryRunPayload(wh webhookConfig, body []byte) error {
+	if err := os.MkdirAll(stateDir(), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dryRunLogPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s POST %s (%s)\n%s\n", time.Now().Format(time.RFC3339), wh.Name, wh.Format, body)
+	return err
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// webhookStandIn answers every request with status and counts the requests
+func webhookStandIn(t *testing.T, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestPostWebhookRetries(t *testing.T) {
+	defer func(d time.Duration) { webhookBackoff = d }(webhookBackoff)
+	webhookBackoff = time.Millisecond
+	tests := []struct {
+		status int
+		calls  int32
+		ok     bool
+	}{
+		{http.StatusOK, 1, true},
+		{http.StatusTooManyRequests, 3, false},
+		{http.StatusBadGateway, 3, false},
+		{http.StatusBadRequest, 1, false},
+		{http.StatusNotFound, 1, false},
+	}
+	for _, tt := range tests {
+		srv, calls := webhookStandIn(t, tt.status)
+		err := postWebhook(webhookConfig{Name: "test", URL: srv.URL, MaxRetries: 2}, []byte(`{}`))
+		if (err == nil) != tt.ok || calls.Load() != tt.calls {
+			t.Errorf("HTTP %d: got %d requests and %v, want %d requests", tt.status, calls.Load(), err, tt.calls)
+		}
+	}
+}
+
+func TestSendNotificationDryRun(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("ONCALL_STATE_DIR", dir)
+	srv, calls := webhookStandIn(t, http.StatusOK)
+	cfg := config{DryRun: true, Webhooks: []webhookConfig{{Name: "test", URL: srv.URL, Format: "json", MaxRetries: 3}}}
+	msg := sendNotificationCmd(cfg, notification{Event: "alert", Title: "IAM down"})()
+	if calls.Load() != 0 {
+		t.Errorf("dry run posted %d requests", calls.Load())
+	}
+	if !strings.HasPrefix(string(msg.(statusMsg)), "Dry run") {
+		t.Errorf("got %q", msg)
+	}
+	logged, err := os.ReadFile(dryRunLogPath())
+	if err != nil || !strings.Contains(string(logged), `"title":"IAM down"`) {
+		t.Errorf("payload not logged: %q %v", logged, err)
+	}
+}
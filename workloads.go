@@ -0,0 +1,450 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	lipgloss "github.com/charmbracelet/lipgloss"
+)
+
+// workload is a Deployment, StatefulSet, DaemonSet, CronJob or a Job not
+// started by a CronJob, with its pods found through ownerReferences
+type workload struct {
+	Kind   string
+	Name   string
+	Images []string // image tags of the pod template
+	Pods   []string
+	// Deployments, StatefulSets and DaemonSets
+	Desired, Ready, Updated, Available int
+	Progress                           string // "complete", "rolling out: ..." or "stalled: ..."
+	// Deployments: the current revision and when its ReplicaSet was created
+	Revision        string
+	RevisionCreated time.Time
+	// CronJobs and Jobs
+	Schedule    string
+	Suspended   bool
+	Active      int
+	LastSuccess time.Time
+	LastFailure time.Time
+}
+
+type workloadsMsg struct {
+	kubeContext string // as listed in; empty when not known yet
+	namespace   string
+	workloads   []workload
+	err         error
+}
+
+// The fields of the workload kinds that are read; kinds differ in which
+// status fields are set
+type kubeObject struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name              string            `json:"name"`
+		UID               string            `json:"uid"`
+		Generation        int64             `json:"generation"`
+		CreationTimestamp time.Time         `json:"creationTimestamp"`
+		Annotations       map[string]string `json:"annotations"`
+		OwnerReferences   []struct {
+			Kind string `json:"kind"`
+			UID  string `json:"uid"`
+		} `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		Replicas *int   `json:"replicas"`
+		Schedule string `json:"schedule"`
+		Suspend  *bool  `json:"suspend"`
+		Template struct {
+			Spec struct {
+				Containers []struct {
+					Image string `json:"image"`
+				} `json:"containers"`
+			} `json:"spec"`
+		} `json:"template"`
+		JobTemplate struct {
+			Spec struct {
+				Template struct {
+					Spec struct {
+						Containers []struct {
+							Image string `json:"image"`
+						} `json:"containers"`
+					} `json:"spec"`
+				} `json:"template"`
+			} `json:"spec"`
+		} `json:"jobTemplate"`
+	} `json:"spec"`
+	Status struct {
+		ObservedGeneration int64 `json:"observedGeneration"`
+		Replicas           int   `json:"replicas"`
+		ReadyReplicas      int   `json:"readyReplicas"`
+		UpdatedReplicas    int   `json:"updatedReplicas"`
+		AvailableReplicas  int   `json:"availableReplicas"`
+		// StatefulSet
+		CurrentRevision string `json:"currentRevision"`
+		UpdateRevision  string `json:"updateRevision"`
+		// DaemonSet
+		DesiredNumberScheduled int `json:"desiredNumberScheduled"`
+		NumberReady            int `json:"numberReady"`
+		UpdatedNumberScheduled int `json:"updatedNumberScheduled"`
+		NumberAvailable        int `json:"numberAvailable"`
+		// CronJob; Jobs are judged by their conditions. "active" is read
+		// separately as CronJobs and Jobs use different types for it.
+		LastSuccessfulTime time.Time `json:"lastSuccessfulTime"`
+		Conditions         []struct {
+			Type               string    `json:"type"`
+			Status             string    `json:"status"`
+			Reason             string    `json:"reason"`
+			LastTransitionTime time.Time `json:"lastTransitionTime"`
+		} `json:"conditions"`
+	} `json:"status"`
+	activeCount int
+}
+
+// condition returns when the condition became true
+func (o kubeObject) condition(conditionType string) (time.Time, bool) {
+	for _, c := range o.Status.Conditions {
+		if c.Type == conditionType && c.Status == "True" {
+			return c.LastTransitionTime, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// getWorkloadsCmd lists the workloads of the given context and namespace, or
+// of the current ones until those are known
+func getWorkloadsCmd(kubeContext, namespace string) tea.Cmd {
+	return func() tea.Msg {
+		args := []string{"get", "deployments,replicasets,statefulsets,daemonsets,jobs,cronjobs,pods", "-o", "json"}
+		if kubeContext != "" && namespace != "" {
+			args = append(args, "--context", kubeContext, "--namespace", namespace)
+		} else {
+			kubeContext, namespace = "", ""
+		}
+		cmd := exec.Command("kubectl", args...)
+		out, err := cmd.Output()
+		if err != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+				err = fmt.Errorf("%s", strings.TrimSpace(string(exitErr.Stderr)))
+			}
+			return workloadsMsg{err: fmt.Errorf("failed to get workloads: %w", err)}
+		}
+		workloads, err := parseWorkloads(out)
+		if err != nil {
+			return workloadsMsg{err: fmt.Errorf("failed to parse workloads: %w", err)}
+		}
+		return workloadsMsg{kubeContext: kubeContext, namespace: namespace, workloads: workloads}
+	}
+}
+
+func parseWorkloads(data []byte) ([]workload, error) {
+	var list struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	objects := make([]kubeObject, 0, len(list.Items))
+	for _, raw := range list.Items {
+		var o kubeObject
+		if err := json.Unmarshal(raw, &o); err != nil {
+			return nil, err
+		}
+		// a count for Jobs, a list of references for CronJobs
+		var active struct {
+			Status struct {
+				Active json.RawMessage `json:"active"`
+			} `json:"status"`
+		}
+		if json.Unmarshal(raw, &active) == nil && len(active.Status.Active) > 0 {
+			var n int
+			var refs []json.RawMessage
+			if json.Unmarshal(active.Status.Active, &n) == nil {
+				o.activeCount = n
+			} else if json.Unmarshal(active.Status.Active, &refs) == nil {
+				o.activeCount = len(refs)
+			}
+		}
+		objects = append(objects, o)
+	}
+	return buildWorkloads(objects), nil
+}
+
+// buildWorkloads links pods to their top-level owner (Pod → ReplicaSet →
+// Deployment, Pod → Job → CronJob) and summarises each workload
+func buildWorkloads(objects []kubeObject) []workload {
+	byUID := map[string]kubeObject{}
+	for _, o := range objects {
+		byUID[o.Metadata.UID] = o
+	}
+	// topOwner follows ownerReferences up to a workload shown in the list
+	var topOwner func(o kubeObject) (kubeObject, bool)
+	topOwner = func(o kubeObject) (kubeObject, bool) {
+		for _, ref := range o.Metadata.OwnerReferences {
+			if owner, ok := byUID[ref.UID]; ok {
+				if top, ok := topOwner(owner); ok {
+					return top, true
+				}
+				return owner, owner.Kind != "ReplicaSet"
+			}
+		}
+		return kubeObject{}, false
+	}
+
+	pods := map[string][]string{}
+	jobs := map[string][]kubeObject{}        // by CronJob UID
+	replicaSets := map[string][]kubeObject{} // by Deployment UID
+	for _, o := range objects {
+		switch o.Kind {
+		case "ReplicaSet":
+			for _, ref := range o.Metadata.OwnerReferences {
+				replicaSets[ref.UID] = append(replicaSets[ref.UID], o)
+			}
+		case "Pod":
+			if owner, ok := topOwner(o); ok {
+				pods[owner.Metadata.UID] = append(pods[owner.Metadata.UID], o.Metadata.Name)
+			}
+		case "Job":
+			if owner, ok := topOwner(o); ok && owner.Kind == "CronJob" {
+				jobs[owner.Metadata.UID] = append(jobs[owner.Metadata.UID], o)
+			}
+		}
+	}
+
+	var workloads []workload
+	for _, o := range objects {
+		w := workload{Kind: o.Kind, Name: o.Metadata.Name, Pods: pods[o.Metadata.UID]}
+		sort.Strings(w.Pods)
+		containers := o.Spec.Template.Spec.Containers
+		switch o.Kind {
+		case "Deployment", "StatefulSet":
+			w.Desired = 1
+			if o.Spec.Replicas != nil {
+				w.Desired = *o.Spec.Replicas
+			}
+			w.Ready, w.Updated, w.Available = o.Status.ReadyReplicas, o.Status.UpdatedReplicas, o.Status.AvailableReplicas
+			w.Progress = rolloutProgress(o, w)
+			if o.Kind == "Deployment" {
+				w.Revision = o.Metadata.Annotations[revisionAnnotation]
+				for _, rs := range replicaSets[o.Metadata.UID] {
+					if w.Revision != "" && rs.Metadata.Annotations[revisionAnnotation] == w.Revision {
+						w.RevisionCreated = rs.Metadata.CreationTimestamp
+					}
+				}
+			}
+		case "DaemonSet":
+			w.Desired, w.Ready, w.Updated, w.Available = o.Status.DesiredNumberScheduled, o.Status.NumberReady, o.Status.UpdatedNumberScheduled, o.Status.NumberAvailable
+			w.Progress = rolloutProgress(o, w)
+		case "Job":
+			if owner, ok := topOwner(o); ok && owner.Kind == "CronJob" {
+				continue // listed under its CronJob
+			}
+			w.Active = o.activeCount
+			if at, ok := o.condition("Complete"); ok {
+				w.LastSuccess = at
+			}
+			if at, ok := o.condition("Failed"); ok {
+				w.LastFailure = at
+			}
+		case "CronJob":
+			containers = o.Spec.JobTemplate.Spec.Template.Spec.Containers
+			w.Schedule = o.Spec.Schedule
+			w.Suspended = o.Spec.Suspend != nil && *o.Spec.Suspend
+			w.Active = o.activeCount
+			w.LastSuccess = o.Status.LastSuccessfulTime
+			for _, job := range jobs[o.Metadata.UID] {
+				if at, ok := job.condition("Complete"); ok && at.After(w.LastSuccess) {
+					w.LastSuccess = at
+				}
+				if at, ok := job.condition("Failed"); ok && at.After(w.LastFailure) {
+					w.LastFailure = at
+				}
+			}
+		default:
+			continue
+		}
+		for _, c := range containers {
+			w.Images = append(w.Images, imageTag(c.Image))
+		}
+		workloads = append(workloads, w)
+	}
+	sort.SliceStable(workloads, func(i, j int) bool {
+		if workloads[i].Kind != workloads[j].Kind {
+			return workloadKindOrder(workloads[i].Kind) < workloadKindOrder(workloads[j].Kind)
+		}
+		return workloads[i].Name < workloads[j].Name
+	})
+	return workloads
+}
+
+// Set by the Deployment controller on a Deployment and its ReplicaSets
+const revisionAnnotation = "deployment.kubernetes.io/revision"
+
+func workloadKindOrder(kind string) int {
+	for i, k := range []string{"Deployment", "StatefulSet", "DaemonSet", "CronJob", "Job"} {
+		if k == kind {
+			return i
+		}
+	}
+	return 99
+}
+
+// rolloutProgress follows the checks of `kubectl rollout status`
+func rolloutProgress(o kubeObject, w workload) string {
+	if o.Metadata.Generation > o.Status.ObservedGeneration {
+		return "rolling out: waiting for the controller"
+	}
+	if o.Kind == "Deployment" {
+		for _, c := range o.Status.Conditions {
+			if c.Type == "Progressing" && c.Reason == "ProgressDeadlineExceeded" {
+				return "stalled: progress deadline exceeded"
+			}
+		}
+	}
+	switch {
+	case w.Updated < w.Desired:
+		return fmt.Sprintf("rolling out: %d of %d updated", w.Updated, w.Desired)
+	case o.Kind == "Deployment" && o.Status.Replicas > w.Updated:
+		return fmt.Sprintf("rolling out: %d old replicas terminating", o.Status.Replicas-w.Updated)
+	case o.Kind == "StatefulSet" && o.Status.UpdateRevision != "" && o.Status.CurrentRevision != o.Status.UpdateRevision:
+		return fmt.Sprintf("rolling out: %d of %d updated", w.Updated, w.Desired)
+	case w.Available < w.Desired:
+		return fmt.Sprintf("rolling out: %d of %d available", w.Available, w.Desired)
+	}
+	return "complete"
+}
+
+// imageTag shortens "registry:5000/team/api:1.4.2" to "api:1.4.2"
+func imageTag(image string) string {
+	if i := strings.LastIndex(image, "/"); i >= 0 {
+		image = image[i+1:]
+	}
+	if name, digest, ok := strings.Cut(image, "@"); ok && len(digest) > 19 {
+		image = name + "@" + digest[:19]
+	}
+	return image
+}
+
+// workloadRow is a line of the workloads view: a workload, or one of its pods
+// when expanded
+type workloadRow struct {
+	workload int
+	pod      string
+}
+
+func workloadKey(w workload) string {
+	return w.Kind + "/" + w.Name
+}
+
+func workloadRows(workloads []workload, expanded map[string]bool) []workloadRow {
+	var rows []workloadRow
+	for i, w := range workloads {
+		rows = append(rows, workloadRow{workload: i})
+		if expanded[workloadKey(w)] {
+			for _, pod := range w.Pods {
+				rows = append(rows, workloadRow{workload: i, pod: pod})
+			}
+		}
+	}
+	return rows
+}
+
+func (w workload) describe(now time.Time) string {
+	switch w.Kind {
+	case "CronJob", "Job":
+		var parts []string
+		if w.Schedule != "" {
+			parts = append(parts, w.Schedule)
+		}
+		if w.Suspended {
+			parts = append(parts, pendingStyle.Render("suspended"))
+		}
+		parts = append(parts, fmt.Sprintf("%d active", w.Active))
+		success := "last success " + ago(w.LastSuccess, now)
+		if !w.LastSuccess.IsZero() {
+			success = statusResolvedStyle.Render(success)
+		}
+		parts = append(parts, success)
+		if !w.LastFailure.IsZero() {
+			failure := "last failure " + ago(w.LastFailure, now)
+			if w.LastFailure.After(w.LastSuccess) {
+				failure = statusUnresolvedStyle.Render(failure)
+			}
+			parts = append(parts, failure)
+		}
+		return strings.Join(parts, " · ")
+	}
+	progress := w.Progress
+	if w.Revision != "" {
+		progress = deployProgress(w)
+	}
+	switch {
+	case strings.HasPrefix(w.Progress, "stalled"):
+		progress = statusUnresolvedStyle.Render(progress)
+	case strings.HasPrefix(w.Progress, "rolling out"):
+		progress = pendingStyle.Render(progress)
+	default:
+		progress = statusResolvedStyle.Render(progress)
+	}
+	ready := fmt.Sprintf("%d/%d ready", w.Ready, w.Desired)
+	if w.Ready < w.Desired {
+		ready = statusUnresolvedStyle.Render(ready)
+	}
+	return fmt.Sprintf("%s · %d updated · %d available · %s", ready, w.Updated, w.Available, progress)
+}
+
+func (m model) workloadsView() string {
+	header := logViewerHeaderStyle.Render(fmt.Sprintf("Workloads · %d", len(m.workloads)))
+	footer := logViewerFooterStyle.Render("↑/↓: Select | Enter/Space: Expand Pods | l: Pod Logs | Esc: Back")
+	now := time.Now()
+	rows := workloadRows(m.workloads, m.workloadExpanded)
+	var lines []string
+	for i, row := range rows {
+		w := m.workloads[row.workload]
+		var line string
+		if row.pod == "" {
+			marker := "▸"
+			if m.workloadExpanded[workloadKey(w)] {
+				marker = "▾"
+			}
+			line = fmt.Sprintf("%s %-11s %-32s %-32s %s", marker, w.Kind, w.Name, strings.Join(w.Images, ","), w.describe(now))
+		} else {
+			status := m.podStatuses[row.pod]
+			style := pendingStyle
+			switch podLevel(status) {
+			case "ok":
+				style = statusResolvedStyle
+			case "error":
+				style = statusUnresolvedStyle
+			}
+			line = fmt.Sprintf("      %-44s %s", row.pod, style.Render(orDash(status)))
+		}
+		if i == m.selectedWorkloadRow {
+			line = highlightStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if m.workloadsErr != "" {
+		lines = append([]string{statusUnresolvedStyle.Render(m.workloadsErr)}, lines...)
+	} else if len(lines) == 0 {
+		lines = []string{logViewerFooterStyle.Render("No workloads in this namespace")}
+	}
+	// keep the selection on screen
+	height := m.height - lipgloss.Height(header) - lipgloss.Height(footer)
+	if height > 0 && len(lines) > height {
+		start := m.selectedWorkloadRow - height/2
+		if start < 0 {
+			start = 0
+		}
+		if start > len(lines)-height {
+			start = len(lines) - height
+		}
+		lines = lines[start : start+height]
+	}
+	body := lipgloss.NewStyle().Height(height).Render(strings.Join(lines, "\n"))
+	return lipgloss.JoinVertical(lipgloss.Left, header, body, footer)
+}
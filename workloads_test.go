@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildWorkloads(t *testing.T) {
+	out := []byte(`{"items": [
+		{"kind": "Pod", "metadata": {"name": "api-7d9f-b", "uid": "p2", "ownerReferences": [{"kind": "ReplicaSet", "uid": "rs5"}]}},
+		{"kind": "Pod", "metadata": {"name": "api-7d9f-a", "uid": "p1", "ownerReferences": [{"kind": "ReplicaSet", "uid": "rs5"}]}},
+		{"kind": "ReplicaSet", "metadata": {"name": "api-6c8e", "uid": "rs4", "creationTimestamp": "2026-10-18T09:00:00Z",
+			"annotations": {"deployment.kubernetes.io/revision": "4"}, "ownerReferences": [{"kind": "Deployment", "uid": "d1"}]}},
+		{"kind": "ReplicaSet", "metadata": {"name": "api-7d9f", "uid": "rs5", "creationTimestamp": "2026-10-19T11:00:00Z",
+			"annotations": {"deployment.kubernetes.io/revision": "5"}, "ownerReferences": [{"kind": "Deployment", "uid": "d1"}]}},
+		{"kind": "Deployment", "metadata": {"name": "api", "uid": "d1", "generation": 7, "annotations": {"deployment.kubernetes.io/revision": "5"}},
+			"spec": {"replicas": 3, "template": {"spec": {"containers": [{"image": "registry:5000/team/api:1.4.2"}, {"image": "envoy:1.31"}]}}},
+			"status": {"observedGeneration": 7, "replicas": 3, "readyReplicas": 2, "updatedReplicas": 3, "availableReplicas": 2}},
+		{"kind": "ReplicaSet", "metadata": {"name": "orphan-5b", "uid": "rs9"}},
+		{"kind": "Pod", "metadata": {"name": "orphan-5b-x", "uid": "p9", "ownerReferences": [{"kind": "ReplicaSet", "uid": "rs9"}]}},
+		{"kind": "StatefulSet", "metadata": {"name": "db", "uid": "s1", "generation": 2},
+			"spec": {"template": {"spec": {"containers": [{"image": "postgres:16"}]}}},
+			"status": {"observedGeneration": 1, "readyReplicas": 1, "updatedReplicas": 1, "availableReplicas": 1}},
+		{"kind": "Pod", "metadata": {"name": "db-0", "uid": "p3", "ownerReferences": [{"kind": "StatefulSet", "uid": "s1"}]}},
+		{"kind": "DaemonSet", "metadata": {"name": "agent", "uid": "ds1"},
+			"status": {"desiredNumberScheduled": 4, "numberReady": 4, "updatedNumberScheduled": 4, "numberAvailable": 4}},
+		{"kind": "CronJob", "metadata": {"name": "backup", "uid": "cj1"},
+			"spec": {"schedule": "0 3 * * *", "suspend": true, "jobTemplate": {"spec": {"template": {"spec": {"containers": [{"image": "backup:2"}]}}}}},
+			"status": {"active": [{"name": "backup-29001"}], "lastSuccessfulTime": "2026-10-18T03:05:00Z"}},
+		{"kind": "Job", "metadata": {"name": "backup-29000", "uid": "j1", "ownerReferences": [{"kind": "CronJob", "uid": "cj1"}]},
+			"status": {"conditions": [{"type": "Failed", "status": "True", "lastTransitionTime": "2026-10-19T03:10:00Z"}]}},
+		{"kind": "Job", "metadata": {"name": "backup-29001", "uid": "j2", "ownerReferences": [{"kind": "CronJob", "uid": "cj1"}]},
+			"status": {"active": 1}},
+		{"kind": "Pod", "metadata": {"name": "backup-29001-q", "uid": "p4", "ownerReferences": [{"kind": "Job", "uid": "j2"}]}},
+		{"kind": "Job", "metadata": {"name": "migrate", "uid": "j3"},
+			"status": {"conditions": [{"type": "Complete", "status": "True", "lastTransitionTime": "2026-10-19T08:00:00Z"}]}},
+		{"kind": "Pod", "metadata": {"name": "debug", "uid": "p5"}}
+	]}`)
+	got, err := parseWorkloads(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(s string) time.Time {
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	want := []workload{
+		{Kind: "Deployment", Name: "api", Images: []string{"api:1.4.2", "envoy:1.31"}, Pods: []string{"api-7d9f-a", "api-7d9f-b"},
+			Desired: 3, Ready: 2, Updated: 3, Available: 2, Progress: "rolling out: 2 of 3 available",
+			Revision: "5", RevisionCreated: at("2026-10-19T11:00:00Z")},
+		{Kind: "StatefulSet", Name: "db", Images: []string{"postgres:16"}, Pods: []string{"db-0"},
+			Desired: 1, Ready: 1, Updated: 1, Available: 1, Progress: "rolling out: waiting for the controller"},
+		{Kind: "DaemonSet", Name: "agent", Desired: 4, Ready: 4, Updated: 4, Available: 4, Progress: "complete"},
+		{Kind: "CronJob", Name: "backup", Images: []string{"backup:2"}, Pods: []string{"backup-29001-q"},
+			Schedule: "0 3 * * *", Suspended: true, Active: 1,
+			LastSuccess: at("2026-10-18T03:05:00Z"), LastFailure: at("2026-10-19T03:10:00Z")},
+		{Kind: "Job", Name: "migrate", LastSuccess: at("2026-10-19T08:00:00Z")},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d workloads %+v, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("workload %d:\ngot  %+v\nwant %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRolloutProgress(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"complete", `{"kind": "Deployment", "spec": {"replicas": 2}, "status": {"replicas": 2, "updatedReplicas": 2, "availableReplicas": 2}}`, "complete"},
+		{"updating", `{"kind": "Deployment", "spec": {"replicas": 4}, "status": {"replicas": 5, "updatedReplicas": 1, "availableReplicas": 4}}`, "rolling out: 1 of 4 updated"},
+		{"old replicas terminating", `{"kind": "Deployment", "spec": {"replicas": 2}, "status": {"replicas": 3, "updatedReplicas": 2, "availableReplicas": 2}}`, "rolling out: 1 old replicas terminating"},
+		{"stalled", `{"kind": "Deployment", "spec": {"replicas": 2}, "status": {"updatedReplicas": 1, "conditions": [{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"}]}}`, "stalled: progress deadline exceeded"},
+		{"statefulset revision", `{"kind": "StatefulSet", "spec": {"replicas": 2}, "status": {"updatedReplicas": 2, "availableReplicas": 2, "currentRevision": "db-1", "updateRevision": "db-2"}}`, "rolling out: 2 of 2 updated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWorkloads([]byte(`{"items": [` + tt.json + `]}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 || got[0].Progress != tt.want {
+				t.Errorf("got %+v, want %q", got, tt.want)
+			}
+		})
+	}
+}